@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"dashboard-server/certs"
+	"dashboard-server/config"
+	"dashboard-server/logging"
+	"dashboard-server/routes"
+	"dashboard-server/services"
+	"dashboard-server/sysinfo"
+)
+
+// runAgent serves this machine's metrics to a main server instead of running
+// the dashboard. It uses no database.
+func runAgent(ctx context.Context) {
+	token := os.Getenv("AGENT_TOKEN")
+	if token == "" {
+		logging.Fatal("AGENT_TOKEN must be set in agent mode")
+	}
+
+	services.StartWorker(ctx, "system-stats", sysinfo.Local().Run)
+
+	port := config.String("AGENT_PORT", "61209")
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           routes.SetupAgentRoutes(token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	certFile, keyFile := os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE")
+	tlsEnabled := certs.Enabled()
+	if tlsEnabled {
+		reloader, err := certs.NewReloader(certFile, keyFile)
+		if err != nil {
+			logging.Fatal("Failed to load TLS certificate", "error", err)
+		}
+		srv.TLSConfig, err = certs.ServerConfig(reloader, "", certs.ClientAuthNone)
+		if err != nil {
+			logging.Fatal("Invalid TLS configuration", "error", err)
+		}
+		services.StartWorker(ctx, "tls-reloader", func(ctx context.Context) {
+			reloader.Watch(ctx, config.Duration("TLS_RELOAD_INTERVAL", 30*time.Second))
+		})
+	}
+
+	go func() {
+		var err error
+		if tlsEnabled {
+			slog.Info("Agent starting with TLS", "port", port)
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			slog.Info("Agent starting", "port", port)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Fatal("Failed to start agent", "error", err)
+		}
+	}()
+
+	<-ctx.Done()
+	slog.Info("Shutdown signal received, stopping agent")
+
+	timeout := config.Duration("SHUTDOWN_TIMEOUT", 15*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Agent shutdown did not complete cleanly", "error", err)
+	}
+	if !services.WaitForWorkers(timeout) {
+		slog.Warn("Timed out waiting for background workers to stop")
+	}
+
+	slog.Info("Agent stopped")
+}
@@ -0,0 +1,70 @@
+// Package agent is the client for servers running in agent mode, which
+// serve the local collector's data to the main server.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"dashboard-server/glances"
+	"dashboard-server/services"
+)
+
+// Path is where agents serve their API.
+const Path = "/api/v1/agent"
+
+var client = services.NewHTTPClient(10*time.Second, false)
+
+func get(ctx context.Context, baseURL, token, endpoint string, data interface{}) error {
+	url := strings.TrimRight(baseURL, "/") + Path + "/" + endpoint
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("agent returned status %d", resp.StatusCode)
+	}
+
+	response := struct {
+		Data interface{} `json:"data"`
+	}{Data: data}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func FetchStats(ctx context.Context, baseURL, token string) (*glances.Stats, error) {
+	stats := &glances.Stats{}
+	if err := get(ctx, baseURL, token, "stats", stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func FetchDetails(ctx context.Context, baseURL, token string) (*glances.Details, error) {
+	details := &glances.Details{}
+	if err := get(ctx, baseURL, token, "details", details); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+func Test(ctx context.Context, baseURL, token string) error {
+	if _, err := FetchStats(ctx, baseURL, token); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	return nil
+}
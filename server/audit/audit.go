@@ -0,0 +1,204 @@
+package audit
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"dashboard-server/glances"
+	"dashboard-server/logging"
+	"dashboard-server/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	EntityDashboard = "dashboard"
+	EntityWidget    = "widget"
+	EntitySection   = "section"
+	EntityShare     = "share"
+	EntityHost      = "host"
+)
+
+const redacted = "[REDACTED]"
+
+// WidgetFields flattens the audited parts of a widget. Config keys are
+// prefixed with "config." so a diff names exactly which setting changed;
+// LastState is runtime data and deliberately left out.
+func WidgetFields(w *models.Widget) map[string]interface{} {
+	fields := map[string]interface{}{
+		"dashboard_id": w.DashboardID,
+		"name":         w.Name,
+		"type":         w.Type,
+		"position":     w.Position,
+		"is_enabled":   w.IsEnabled,
+	}
+	if w.SectionID != nil {
+		fields["section_id"] = *w.SectionID
+	}
+	if w.Source != "" {
+		fields["source"] = w.Source
+	}
+	for breakpoint, rect := range w.Layout {
+		fields["layout."+breakpoint] = fmt.Sprintf("%d,%d %dx%d", rect.X, rect.Y, rect.W, rect.H)
+	}
+	flatten(fields, "config.", w.Config)
+	return fields
+}
+
+// DashboardFields flattens a dashboard. The Glances config is split into
+// its settings so the password is redacted like any other secret; a config
+// that does not parse is never logged verbatim.
+func DashboardFields(d *models.Dashboard) map[string]interface{} {
+	fields := map[string]interface{}{
+		"name":        d.Name,
+		"description": d.Description,
+	}
+	if config, err := glances.ParseConfig(d.GlancesConfig); err == nil {
+		fields["glances_config.url"] = config.URL
+		fields["glances_config.username"] = config.Username
+		fields["glances_config.password"] = config.Password
+	} else if d.GlancesConfig != "" {
+		fields["glances_config"] = redacted
+	}
+	for breakpoint, columns := range d.Columns {
+		fields["columns."+breakpoint] = columns
+	}
+	return fields
+}
+
+func SectionFields(s *models.Section) map[string]interface{} {
+	return map[string]interface{}{
+		"dashboard_id": s.DashboardID,
+		"title":        s.Title,
+		"position":     s.Position,
+		"collapsed":    s.Collapsed,
+	}
+}
+
+func HostFields(h *models.MonitoredHost) map[string]interface{} {
+	return map[string]interface{}{
+		"name":     h.Name,
+		"type":     h.Type,
+		"url":      h.URL,
+		"username": h.Username,
+		"password": h.Password,
+		"enabled":  h.Enabled,
+	}
+}
+
+func flatten(fields map[string]interface{}, prefix string, data map[string]interface{}) {
+	for key, value := range data {
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(fields, prefix+key+".", nested)
+			continue
+		}
+		fields[prefix+key] = value
+	}
+}
+
+// Diff returns {"field": {"old": ..., "new": ...}} for every field that
+// differs. Either side may be nil for creates and deletes. Sensitive values
+// are replaced so the log shows that a secret changed, never what it is.
+func Diff(before, after map[string]interface{}) models.JSON {
+	keys := make(map[string]struct{})
+	for key := range before {
+		keys[key] = struct{}{}
+	}
+	for key := range after {
+		keys[key] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	changes := models.JSON{}
+	for _, key := range sorted {
+		oldValue, hadOld := before[key]
+		newValue, hasNew := after[key]
+		if hadOld && hasNew && reflect.DeepEqual(normalize(oldValue), normalize(newValue)) {
+			continue
+		}
+
+		change := map[string]interface{}{}
+		if hadOld {
+			change["old"] = redact(key, oldValue)
+		}
+		if hasNew {
+			change["new"] = redact(key, newValue)
+		}
+		changes[key] = change
+	}
+	return changes
+}
+
+// normalize makes values decoded from JSON (float64) comparable with the
+// typed values read from the model.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return value
+}
+
+func redact(key string, value interface{}) interface{} {
+	for _, segment := range strings.Split(key, ".") {
+		if models.IsSensitiveField(segment) {
+			return redacted
+		}
+	}
+	if s, ok := value.(string); ok {
+		return logging.Redact(s)
+	}
+	return value
+}
+
+// Record appends an audit entry using tx, so it commits or rolls back with
+// the change it describes. Updates that change nothing are not recorded.
+func Record(tx *gorm.DB, c *gin.Context, action, entityType string, entityID uint, before, after map[string]interface{}) error {
+	actor := c.GetString("user")
+	if actor == "" {
+		actor = "anonymous"
+	}
+
+	return write(tx, models.AuditLog{
+		Actor:      actor,
+		SourceIP:   c.ClientIP(),
+		RequestID:  c.GetString("request_id"),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}, before, after)
+}
+
+// RecordSystem is Record for changes made by background jobs.
+func RecordSystem(tx *gorm.DB, action, entityType string, entityID uint, before, after map[string]interface{}) error {
+	return write(tx, models.AuditLog{
+		Actor:      "system",
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+	}, before, after)
+}
+
+func write(tx *gorm.DB, entry models.AuditLog, before, after map[string]interface{}) error {
+	entry.Changes = Diff(before, after)
+	if entry.Action == models.AuditActionUpdate && len(entry.Changes) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"dashboard-server/models"
+)
+
+func TestDashboardDiffRedactsGlancesPassword(t *testing.T) {
+	before := &models.Dashboard{
+		Name:          "Home",
+		GlancesConfig: `{"url":"http://glances:61208","username":"admin","password":"old-secret"}`,
+	}
+	after := &models.Dashboard{
+		Name:          "Home",
+		GlancesConfig: `{"url":"http://glances:61208","username":"admin","password":"new-secret"}`,
+	}
+
+	changes := Diff(DashboardFields(before), DashboardFields(after))
+
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "secret") {
+		t.Fatalf("password leaked into the diff: %s", raw)
+	}
+	change, ok := changes["glances_config.password"].(map[string]interface{})
+	if !ok || change["old"] != redacted || change["new"] != redacted {
+		t.Errorf("password change = %v, want both sides redacted", changes["glances_config.password"])
+	}
+	if _, ok := changes["glances_config.url"]; ok {
+		t.Error("unchanged URL reported as changed")
+	}
+}
+
+func TestDashboardFieldsInvalidGlancesConfig(t *testing.T) {
+	fields := DashboardFields(&models.Dashboard{GlancesConfig: `{"password":"hunter2"`})
+	if fields["glances_config"] != redacted {
+		t.Errorf("glances_config = %v, want %q", fields["glances_config"], redacted)
+	}
+
+	fields = DashboardFields(&models.Dashboard{})
+	for key := range fields {
+		if strings.HasPrefix(key, "glances_config") {
+			t.Errorf("dashboard without Glances has field %s", key)
+		}
+	}
+}
+
+func TestWidgetDiffRedactsNestedSecrets(t *testing.T) {
+	before := &models.Widget{Name: "Sonarr", Config: models.JSON{"serverUrl": "http://sonarr", "apiKey": "abc"}}
+	after := &models.Widget{Name: "Sonarr", Config: models.JSON{"serverUrl": "http://sonarr", "apiKey": "def", "auth": map[string]interface{}{"password": "x"}}}
+
+	changes := Diff(WidgetFields(before), WidgetFields(after))
+	for _, key := range []string{"config.apiKey", "config.auth.password"} {
+		change, ok := changes[key].(map[string]interface{})
+		if !ok || change["new"] != redacted {
+			t.Errorf("%s = %v, want redacted", key, changes[key])
+		}
+	}
+}
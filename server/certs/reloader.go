@@ -0,0 +1,158 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader serves a certificate/key pair from disk and picks up replacements
+// (e.g. from certbot or cert-manager) without restarting the server.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu          sync.RWMutex
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+}
+
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Watch polls the files every interval until ctx is cancelled. A failed
+// reload keeps serving the previous certificate.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := r.changed()
+			if err != nil {
+				slog.Warn("Failed to check TLS certificate files", "error", err)
+				continue
+			}
+			if !changed {
+				continue
+			}
+			if err := r.reload(); err != nil {
+				slog.Error("Failed to reload TLS certificate, keeping the previous one", "error", err)
+				continue
+			}
+			slog.Info("Reloaded TLS certificate", "cert_file", r.certFile)
+		}
+	}
+}
+
+func (r *Reloader) changed() (bool, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !certInfo.ModTime().Equal(r.certModTime) || !keyInfo.ModTime().Equal(r.keyModTime), nil
+}
+
+func (r *Reloader) reload() error {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load key pair: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.certModTime = certInfo.ModTime()
+	r.keyModTime = keyInfo.ModTime()
+	return nil
+}
+
+type ClientAuthMode string
+
+const (
+	// ClientAuthNone does not ask for client certificates.
+	ClientAuthNone ClientAuthMode = ""
+	// ClientAuthAPI verifies certificates when offered and leaves it to the
+	// API routes to demand one, so the UI and health checks stay reachable.
+	ClientAuthAPI ClientAuthMode = "api"
+	// ClientAuthRequire rejects every connection without a valid certificate.
+	ClientAuthRequire ClientAuthMode = "require"
+)
+
+// Enabled reports whether TLS_CERT_FILE and TLS_KEY_FILE are both set, which
+// is when the server listens with TLS.
+func Enabled() bool {
+	return os.Getenv("TLS_CERT_FILE") != "" && os.Getenv("TLS_KEY_FILE") != ""
+}
+
+func ServerConfig(reloader *Reloader, clientCAFile string, mode ClientAuthMode) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	if mode == ClientAuthNone {
+		return tlsConfig, nil
+	}
+	if mode != ClientAuthAPI && mode != ClientAuthRequire {
+		return nil, fmt.Errorf("unknown client auth mode %q", mode)
+	}
+	if clientCAFile == "" {
+		return nil, errors.New("client certificate auth requires a client CA file")
+	}
+
+	caPEM, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("client CA file contains no certificates")
+	}
+
+	tlsConfig.ClientCAs = pool
+	if mode == ClientAuthRequire {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	} else {
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
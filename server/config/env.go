@@ -0,0 +1,85 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func String(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// List splits a comma-separated variable. A variable that is set but empty
+// yields an empty list rather than the fallback.
+func List(name string, fallback []string) []string {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return fallback
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func Bool(name string, fallback bool) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Warn("Invalid boolean environment variable, using default", "name", name, "value", value)
+		return fallback
+	}
+	return parsed
+}
+
+func Int(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("Invalid integer environment variable, using default", "name", name, "value", value)
+		return fallback
+	}
+	return parsed
+}
+
+func Duration(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Warn("Invalid duration environment variable, using default", "name", name, "value", value)
+		return fallback
+	}
+	return parsed
+}
+
+// BasePath returns BASE_PATH normalised to "" or "/prefix" without a
+// trailing slash, so it can be prepended to any absolute route.
+func BasePath() string {
+	basePath := strings.Trim(os.Getenv("BASE_PATH"), "/ ")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
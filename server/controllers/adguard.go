@@ -1,16 +1,20 @@
 package controllers
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	"dashboard-server/database"
+	"dashboard-server/metrics"
 	"dashboard-server/models"
+	"dashboard-server/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -44,7 +48,7 @@ type AdGuardStatsResponse struct {
 func TestAdGuardConnection(c *gin.Context) {
 	var config AdGuardTestConfig
 	if err := c.ShouldBindJSON(&config); err != nil {
-		fmt.Println("Error binding JSON:", err)
+		slog.DebugContext(c.Request.Context(), "Invalid test configuration", "integration", "adguard-home", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration: " + err.Error()})
 		return
 	}
@@ -64,7 +68,7 @@ func TestAdGuardConnection(c *gin.Context) {
 		return
 	}
 
-	statsResponse, statusCode, err := proxyAdGuardRequest(config.ServerURL, config.Username, config.Password)
+	statsResponse, statusCode, err := proxyAdGuardRequest(c.Request.Context(), config.ServerURL, config.Username, config.Password)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
@@ -111,26 +115,52 @@ func ProxyAdGuardStats(c *gin.Context) {
 		return
 	}
 
-	statsResponse, statusCode, err := proxyAdGuardRequest(serverURL, username, password)
+	start := time.Now()
+	stats, statusCode, err := fetchAdGuardStats(c.Request.Context(), serverURL, username, password)
+	if err == nil && stats == nil {
+		metrics.ObserveFetch("adguard-home", start, fmt.Errorf("HTTP %d", statusCode))
+		c.Status(statusCode)
+		return
+	}
+	metrics.ObserveFetch("adguard-home", start, err)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.Data(statusCode, "application/json", statsResponse)
+	metrics.SetWidgetValues(widget.ID, widget.Name, widget.Type, map[string]float64{
+		"total_queries":          float64(stats.TotalQueries),
+		"blocked_queries":        float64(stats.BlockedQueries),
+		"blocking_percentage":    stats.BlockingPercentage,
+		"avg_processing_seconds": stats.AvgProcessingTime,
+	})
+
+	c.JSON(statusCode, stats)
+}
+
+func proxyAdGuardRequest(ctx context.Context, serverURL, username, password string) ([]byte, int, error) {
+	stats, statusCode, err := fetchAdGuardStats(ctx, serverURL, username, password)
+	if err != nil || stats == nil {
+		return nil, statusCode, err
+	}
+
+	responseData, err := json.Marshal(stats)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to marshal response: %v", err)
+	}
+
+	return responseData, statusCode, nil
 }
 
-func proxyAdGuardRequest(serverURL, username, password string) ([]byte, int, error) {
+func fetchAdGuardStats(ctx context.Context, serverURL, username, password string) (*AdGuardStatsResponse, int, error) {
 	if serverURL[len(serverURL)-1] == '/' {
 		serverURL = serverURL[:len(serverURL)-1]
 	}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+	client := services.NewHTTPClient(10*time.Second, false)
 
 	statsURL := fmt.Sprintf("%s/control/stats", serverURL)
-	statsData, statusCode, err := makeAdGuardRequest(client, statsURL, username, password)
+	statsData, statusCode, err := makeAdGuardRequest(ctx, client, statsURL, username, password)
 	if err != nil || statusCode != http.StatusOK {
 		return nil, statusCode, err
 	}
@@ -138,7 +168,7 @@ func proxyAdGuardRequest(serverURL, username, password string) ([]byte, int, err
 	stats := &AdGuardStatsResponse{}
 
 	if err := json.Unmarshal(statsData, &stats); err != nil {
-		fmt.Printf("DEBUG AdGuard: Unmarshal error: %v\n", err)
+		slog.DebugContext(ctx, "Failed to parse AdGuard stats response", "error", err)
 		return nil, http.StatusInternalServerError, fmt.Errorf("failed to parse stats response: %v", err)
 	}
 
@@ -152,7 +182,7 @@ func proxyAdGuardRequest(serverURL, username, password string) ([]byte, int, err
 	}
 
 	versionURL := fmt.Sprintf("%s/control/version.json", serverURL)
-	versionData, _, versionErr := makeAdGuardRequest(client, versionURL, username, password)
+	versionData, _, versionErr := makeAdGuardRequest(ctx, client, versionURL, username, password)
 
 	if versionErr == nil {
 		var version AdGuardVersionResponse
@@ -161,16 +191,11 @@ func proxyAdGuardRequest(serverURL, username, password string) ([]byte, int, err
 		}
 	}
 
-	responseData, err := json.Marshal(stats)
-	if err != nil {
-		return nil, http.StatusInternalServerError, fmt.Errorf("failed to marshal response: %v", err)
-	}
-
-	return responseData, statusCode, nil
+	return stats, statusCode, nil
 }
 
-func makeAdGuardRequest(client *http.Client, url, username, password string) ([]byte, int, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func makeAdGuardRequest(ctx context.Context, client *http.Client, url, username, password string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, http.StatusInternalServerError, fmt.Errorf("failed to create request: %v", err)
 	}
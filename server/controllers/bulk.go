@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"dashboard-server/audit"
+	"dashboard-server/database"
+	"dashboard-server/layout"
+	"dashboard-server/models"
+	"dashboard-server/revisions"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WidgetPlacement struct {
+	ID          uint  `json:"id" binding:"required"`
+	DashboardID *uint `json:"dashboard_id"`
+	Position    int   `json:"position"`
+}
+
+type ReorderWidgetsRequest struct {
+	Widgets []WidgetPlacement `json:"widgets" binding:"required,min=1,dive"`
+}
+
+type CloneRequest struct {
+	Name        string `json:"name"`
+	DashboardID uint   `json:"dashboard_id"`
+}
+
+var errInvalidPlacement = errors.New("invalid placement")
+
+// ReorderWidgets sets position and, optionally, dashboard for many widgets
+// in one transaction. Only those two columns are written.
+func ReorderWidgets(c *gin.Context) {
+	var request ReorderWidgetsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var updated []models.Widget
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		seen := make(map[uint]bool, len(request.Widgets))
+		var sourceDashboards []uint
+
+		for _, placement := range request.Widgets {
+			if seen[placement.ID] {
+				return fmt.Errorf("%w: widget %d listed twice", errInvalidPlacement, placement.ID)
+			}
+			seen[placement.ID] = true
+
+			var widget models.Widget
+			if err := tx.First(&widget, placement.ID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("%w: widget %d not found", errInvalidPlacement, placement.ID)
+				}
+				return err
+			}
+			before := audit.WidgetFields(&widget)
+
+			dashboardID := widget.DashboardID
+			if placement.DashboardID != nil && *placement.DashboardID != widget.DashboardID {
+				var dashboard models.Dashboard
+				if err := tx.First(&dashboard, *placement.DashboardID).Error; err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return fmt.Errorf("%w: dashboard %d not found", errInvalidPlacement, *placement.DashboardID)
+					}
+					return err
+				}
+				sourceDashboards = append(sourceDashboards, widget.DashboardID)
+				dashboardID = dashboard.ID
+			}
+
+			updates := map[string]interface{}{
+				"dashboard_id": dashboardID,
+				"position":     placement.Position,
+			}
+			if dashboardID != widget.DashboardID {
+				// Sections and grid rectangles belong to the old dashboard.
+				widget.DashboardID = dashboardID
+				widget.SectionID = nil
+				if err := layout.PlaceInDashboard(tx, &widget); err != nil {
+					return err
+				}
+				updates["section_id"] = nil
+				updates["layout"] = widget.Layout
+			}
+			if err := tx.Model(&widget).Updates(updates).Error; err != nil {
+				return err
+			}
+			widget.Position = placement.Position
+
+			if err := audit.Record(tx, c, models.AuditActionUpdate, audit.EntityWidget, widget.ID, before, audit.WidgetFields(&widget)); err != nil {
+				return err
+			}
+			updated = append(updated, widget)
+		}
+
+		return revisions.SaveWidgets(tx, c, updated, sourceDashboards...)
+	})
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errInvalidPlacement) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	responses := make([]models.WidgetResponse, 0, len(updated))
+	for i := range updated {
+		responses = append(responses, updated[i].ToResponse())
+	}
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+// CloneWidget copies a widget, secrets included, to the end of the same or
+// another dashboard.
+func CloneWidget(c *gin.Context) {
+	id := c.Param("id")
+
+	var request CloneRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var source models.Widget
+	if err := database.DB.First(&source, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Widget not found"})
+		return
+	}
+
+	dashboardID := source.DashboardID
+	if request.DashboardID != 0 {
+		dashboardID = request.DashboardID
+	}
+
+	var dashboard models.Dashboard
+	if err := database.DB.First(&dashboard, dashboardID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Dashboard not found"})
+		return
+	}
+
+	clone := cloneWidget(&source, dashboard.ID)
+	if dashboard.ID == source.DashboardID {
+		clone.SectionID = source.SectionID
+		clone.Layout = source.Layout
+	}
+	if request.Name != "" {
+		clone.Name = request.Name
+	} else if dashboard.ID == source.DashboardID {
+		clone.Name = source.Name + " (copy)"
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var maxPosition *int
+		if err := tx.Model(&models.Widget{}).Where("dashboard_id = ?", dashboard.ID).
+			Select("MAX(position)").Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+		if maxPosition != nil {
+			clone.Position = *maxPosition + 1
+		}
+
+		if err := layout.PlaceInDashboard(tx, &clone); err != nil {
+			return err
+		}
+		if err := tx.Create(&clone).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, c, models.AuditActionCreate, audit.EntityWidget, clone.ID, nil, audit.WidgetFields(&clone)); err != nil {
+			return err
+		}
+		return revisions.SaveWidget(tx, c, &clone)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": clone.ToResponse()})
+}
+
+// CloneDashboard deep-copies a dashboard and all of its widgets.
+func CloneDashboard(c *gin.Context) {
+	id := c.Param("id")
+
+	var request CloneRequest
+	if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var source models.Dashboard
+	if err := database.DB.Preload("Widgets").Preload("Sections").First(&source, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dashboard not found"})
+		return
+	}
+
+	clone := models.Dashboard{
+		Name:          source.Name + " (copy)",
+		Description:   source.Description,
+		GlancesConfig: source.GlancesConfig,
+		Columns:       source.Columns,
+	}
+	if request.Name != "" {
+		clone.Name = request.Name
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Widgets", "Sections").Create(&clone).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, c, models.AuditActionCreate, audit.EntityDashboard, clone.ID, nil, audit.DashboardFields(&clone)); err != nil {
+			return err
+		}
+
+		sectionIDs := make(map[uint]uint, len(source.Sections))
+		for _, section := range source.Sections {
+			copied := models.Section{
+				DashboardID: clone.ID,
+				Title:       section.Title,
+				Position:    section.Position,
+				Collapsed:   section.Collapsed,
+			}
+			if err := tx.Create(&copied).Error; err != nil {
+				return err
+			}
+			if err := audit.Record(tx, c, models.AuditActionCreate, audit.EntitySection, copied.ID, nil, audit.SectionFields(&copied)); err != nil {
+				return err
+			}
+			sectionIDs[section.ID] = copied.ID
+			clone.Sections = append(clone.Sections, copied)
+		}
+
+		for i := range source.Widgets {
+			widget := cloneWidget(&source.Widgets[i], clone.ID)
+			if source.Widgets[i].SectionID != nil {
+				if sectionID, ok := sectionIDs[*source.Widgets[i].SectionID]; ok {
+					widget.SectionID = &sectionID
+				}
+			}
+			widget.Layout = source.Widgets[i].Layout
+			if err := tx.Create(&widget).Error; err != nil {
+				return err
+			}
+			if err := audit.Record(tx, c, models.AuditActionCreate, audit.EntityWidget, widget.ID, nil, audit.WidgetFields(&widget)); err != nil {
+				return err
+			}
+			clone.Widgets = append(clone.Widgets, widget)
+		}
+
+		return revisions.SaveWidgets(tx, c, clone.Widgets, clone.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": clone.ToResponse()})
+}
+
+func cloneWidget(source *models.Widget, dashboardID uint) models.Widget {
+	return models.Widget{
+		DashboardID: dashboardID,
+		Name:        source.Name,
+		Type:        source.Type,
+		Position:    source.Position,
+		Config:      source.Config.Clone(),
+		IsEnabled:   source.IsEnabled,
+	}
+}
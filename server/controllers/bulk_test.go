@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dashboard-server/database"
+	"dashboard-server/models"
+)
+
+func TestCloneKeepsDisabledWidgets(t *testing.T) {
+	dashboard := setupDB(t)
+	disabled := createTestWidget(t, models.Widget{DashboardID: dashboard.ID, Name: "Sonarr", Type: "sonarr", Config: models.JSON{}})
+	if err := database.DB.Model(&disabled).Update("is_enabled", false).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var widgetResponse struct {
+		Data models.WidgetResponse `json:"data"`
+	}
+	w := serve(t, http.MethodPost, "/widgets/:id/clone", fmt.Sprintf("/widgets/%d/clone", disabled.ID), nil, &widgetResponse, CloneWidget)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("clone widget: status %d: %s", w.Code, w.Body)
+	}
+
+	var dashboardResponse struct {
+		Data models.DashboardResponse `json:"data"`
+	}
+	w = serve(t, http.MethodPost, "/dashboards/:id/clone", fmt.Sprintf("/dashboards/%d/clone", dashboard.ID), nil, &dashboardResponse, CloneDashboard)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("clone dashboard: status %d: %s", w.Code, w.Body)
+	}
+
+	var copies []models.Widget
+	if err := database.DB.Where("id <> ?", disabled.ID).Find(&copies).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(copies) != 3 {
+		t.Fatalf("got %d copies, want 3", len(copies))
+	}
+	for _, widget := range copies {
+		if widget.IsEnabled {
+			t.Errorf("copy %d (%s) of a disabled widget is enabled", widget.ID, widget.Name)
+		}
+	}
+}
+
+func TestReorderWidgets(t *testing.T) {
+	home := setupDB(t)
+	media := models.Dashboard{Name: "Media"}
+	if err := database.DB.Omit("Sections").Create(&media).Error; err != nil {
+		t.Fatal(err)
+	}
+	section := models.Section{DashboardID: home.ID, Title: "Downloads"}
+	if err := database.DB.Create(&section).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	topLeft := models.WidgetLayout{}
+	for _, breakpoint := range models.Breakpoints {
+		topLeft[breakpoint] = models.GridRect{X: 0, Y: 0, W: 4, H: 2}
+	}
+	sonarr := createTestWidget(t, models.Widget{DashboardID: home.ID, SectionID: &section.ID, Name: "Sonarr", Type: "sonarr", Position: 0, Layout: topLeft, IsEnabled: true})
+	radarr := createTestWidget(t, models.Widget{DashboardID: home.ID, Name: "Radarr", Type: "radarr", Position: 1, Layout: topLeft, IsEnabled: true})
+	plex := createTestWidget(t, models.Widget{DashboardID: media.ID, Name: "Plex", Type: "plex", Position: 0, Layout: topLeft, IsEnabled: true})
+
+	type placement struct {
+		dashboardID uint
+		position    int
+	}
+	snapshot := func() map[uint]placement {
+		t.Helper()
+		var widgets []models.Widget
+		if err := database.DB.Find(&widgets).Error; err != nil {
+			t.Fatal(err)
+		}
+		placements := make(map[uint]placement, len(widgets))
+		for _, widget := range widgets {
+			placements[widget.ID] = placement{widget.DashboardID, widget.Position}
+		}
+		return placements
+	}
+	auditCount := func() int64 {
+		t.Helper()
+		var count int64
+		database.DB.Model(&models.AuditLog{}).Count(&count)
+		return count
+	}
+	reorder := func(widgets ...map[string]interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		body := map[string]interface{}{"widgets": widgets}
+		return serve(t, http.MethodPost, "/widgets/reorder", "/widgets/reorder", body, nil, ReorderWidgets)
+	}
+
+	t.Run("moves across dashboards", func(t *testing.T) {
+		w := reorder(
+			map[string]interface{}{"id": sonarr.ID, "dashboard_id": media.ID, "position": 1},
+			map[string]interface{}{"id": radarr.ID, "position": 0},
+		)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body)
+		}
+
+		var moved models.Widget
+		database.DB.First(&moved, sonarr.ID)
+		if moved.DashboardID != media.ID || moved.Position != 1 || moved.SectionID != nil {
+			t.Errorf("moved widget = dashboard %d position %d section %v", moved.DashboardID, moved.Position, moved.SectionID)
+		}
+		if rect := moved.Layout[models.BreakpointDesktop]; rect.Overlaps(plex.Layout[models.BreakpointDesktop]) {
+			t.Errorf("moved widget %+v overlaps Plex on its new dashboard", rect)
+		}
+		if got := snapshot()[radarr.ID]; got != (placement{home.ID, 0}) {
+			t.Errorf("radarr = %+v", got)
+		}
+	})
+
+	for _, tt := range []struct {
+		name    string
+		widgets []map[string]interface{}
+	}{
+		{"unknown widget", []map[string]interface{}{
+			{"id": radarr.ID, "dashboard_id": media.ID, "position": 5},
+			{"id": plex.ID, "position": 9},
+			{"id": 9999, "position": 0},
+		}},
+		{"unknown dashboard", []map[string]interface{}{
+			{"id": plex.ID, "position": 9},
+			{"id": radarr.ID, "dashboard_id": 9999, "position": 5},
+		}},
+		{"duplicate widget", []map[string]interface{}{
+			{"id": radarr.ID, "position": 5},
+			{"id": plex.ID, "position": 9},
+			{"id": radarr.ID, "position": 6},
+		}},
+	} {
+		t.Run(tt.name+" rolls back the batch", func(t *testing.T) {
+			before, audits := snapshot(), auditCount()
+
+			w := reorder(tt.widgets...)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status %d, want 400: %s", w.Code, w.Body)
+			}
+
+			after := snapshot()
+			for id, want := range before {
+				if after[id] != want {
+					t.Errorf("widget %d = %+v, want %+v", id, after[id], want)
+				}
+			}
+			if got := auditCount(); got != audits {
+				t.Errorf("%d audit entries written by a failed batch", got-audits)
+			}
+		})
+	}
+}
@@ -2,10 +2,16 @@ package controllers
 
 import (
 	"net/http"
+	"reflect"
 
+	"dashboard-server/audit"
 	"dashboard-server/models"
 	"dashboard-server/database"
+	"dashboard-server/layout"
+	"dashboard-server/revisions"
+	"dashboard-server/trash"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 )
 
@@ -13,7 +19,7 @@ import (
 func GetDashboards(c *gin.Context) {
 	var dashboards []models.Dashboard
 
-	result := database.DB.Preload("Widgets").Find(&dashboards)
+	result := database.DB.Preload("Widgets").Preload("Sections", orderByPosition).Find(&dashboards)
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
 		return
@@ -31,7 +37,7 @@ func GetDashboard(c *gin.Context) {
 	id := c.Param("id")
 
 	var dashboard models.Dashboard
-	result := database.DB.Preload("Widgets").First(&dashboard, id)
+	result := database.DB.Preload("Widgets").Preload("Sections", orderByPosition).First(&dashboard, id)
 	if result.Error != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Dashboard not found"})
 		return
@@ -47,10 +53,22 @@ func CreateDashboard(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := layout.CheckColumns(dashboard.Columns); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	result := database.DB.Create(&dashboard)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Sections").Create(&dashboard).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, c, models.AuditActionCreate, audit.EntityDashboard, dashboard.ID, nil, audit.DashboardFields(&dashboard)); err != nil {
+			return err
+		}
+		return revisions.SaveDashboard(tx, c, dashboard.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -65,13 +83,43 @@ func UpdateDashboard(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Dashboard not found"})
 		return
 	}
+	before := audit.DashboardFields(&dashboard)
+	columns := dashboard.Columns.Resolved()
 
 	if err := c.ShouldBindJSON(&dashboard); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	database.DB.Save(&dashboard)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Sections").Save(&dashboard).Error; err != nil {
+			return err
+		}
+
+		if !reflect.DeepEqual(columns, dashboard.Columns.Resolved()) {
+			if err := layout.CheckColumns(dashboard.Columns); err != nil {
+				return err
+			}
+			var widgets []models.Widget
+			if err := tx.Where("dashboard_id = ?", dashboard.ID).Find(&widgets).Error; err != nil {
+				return err
+			}
+			if conflicts := layout.Validate(dashboard.Columns, widgets); len(conflicts) > 0 {
+				return &layoutConflictError{conflicts: conflicts}
+			}
+		}
+
+		if err := audit.Record(tx, c, models.AuditActionUpdate, audit.EntityDashboard, dashboard.ID, before, audit.DashboardFields(&dashboard)); err != nil {
+			return err
+		}
+		return revisions.SaveDashboard(tx, c, dashboard.ID)
+	})
+	if err != nil {
+		if !respondLayoutError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"data": dashboard.ToResponse()})
 }
 
@@ -84,9 +132,13 @@ func DeleteDashboard(c *gin.Context) {
 		return
 	}
 
-	database.DB.Where("dashboard_id = ?", id).Delete(&models.Widget{})
-
-	database.DB.Delete(&dashboard)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return trash.DeleteDashboard(tx, c, &dashboard)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Dashboard deleted successfully"})
 }
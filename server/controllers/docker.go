@@ -0,0 +1,313 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"dashboard-server/audit"
+	"dashboard-server/database"
+	"dashboard-server/metrics"
+	"dashboard-server/models"
+	"dashboard-server/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DockerConfig struct {
+	Endpoint      string `json:"endpoint"`
+	ShowStopped   bool   `json:"showStopped"`
+	AllowActions  bool   `json:"allowActions"`
+	TLSSkipVerify bool   `json:"tlsSkipVerify"`
+}
+
+type DockerContainerSummary struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Image         string  `json:"image"`
+	State         string  `json:"state"`
+	Status        string  `json:"status"`
+	Health        string  `json:"health"`
+	RestartCount  int     `json:"restartCount"`
+	CPUPercent    float64 `json:"cpuPercent"`
+	MemoryUsage   uint64  `json:"memoryUsage"`
+	MemoryLimit   uint64  `json:"memoryLimit"`
+	MemoryPercent float64 `json:"memoryPercent"`
+}
+
+type DockerStats struct {
+	Running      int                      `json:"running"`
+	Stopped      int                      `json:"stopped"`
+	Unhealthy    int                      `json:"unhealthy"`
+	Restarting   int                      `json:"restarting"`
+	Total        int                      `json:"total"`
+	AllowActions bool                     `json:"allowActions"`
+	Containers   []DockerContainerSummary `json:"containers"`
+	Alerts       []Alert                  `json:"alerts"`
+}
+
+// dockerStatsConcurrency bounds the per-container inspect and stats calls.
+// Each stats call takes about a second because the engine samples CPU.
+const dockerStatsConcurrency = 8
+
+func dockerConfigFromWidget(config models.JSON) DockerConfig {
+	dockerConfig := DockerConfig{}
+	if endpoint, ok := config["endpoint"].(string); ok {
+		dockerConfig.Endpoint = endpoint
+	}
+	if showStopped, ok := config["showStopped"].(bool); ok {
+		dockerConfig.ShowStopped = showStopped
+	}
+	if allowActions, ok := config["allowActions"].(bool); ok {
+		dockerConfig.AllowActions = allowActions
+	}
+	if skipVerify, ok := config["tlsSkipVerify"].(bool); ok {
+		dockerConfig.TLSSkipVerify = skipVerify
+	}
+	return dockerConfig
+}
+
+func loadDockerWidget(c *gin.Context) (*models.Widget, bool) {
+	widgetID, err := strconv.ParseUint(c.Param("widget_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid widget ID"})
+		return nil, false
+	}
+
+	widget := &models.Widget{}
+	if err := database.DB.First(widget, uint(widgetID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Widget not found"})
+		return nil, false
+	}
+
+	if widget.Type != "docker" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Widget is not a Docker widget"})
+		return nil, false
+	}
+	return widget, true
+}
+
+func ProxyDockerStats(c *gin.Context) {
+	widget, ok := loadDockerWidget(c)
+	if !ok {
+		return
+	}
+	config := dockerConfigFromWidget(widget.Config)
+
+	start := time.Now()
+	stats, err := fetchDockerStats(c.Request.Context(), config)
+	metrics.ObserveFetch("docker", start, err)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("Failed to fetch Docker stats: %v", err),
+		})
+		return
+	}
+
+	metrics.SetWidgetValues(widget.ID, widget.Name, widget.Type, map[string]float64{
+		"running_containers":    float64(stats.Running),
+		"stopped_containers":    float64(stats.Stopped),
+		"unhealthy_containers":  float64(stats.Unhealthy),
+		"restarting_containers": float64(stats.Restarting),
+		"total_containers":      float64(stats.Total),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+func TestDockerConnection(c *gin.Context) {
+	var config DockerConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration", "details": err.Error()})
+		return
+	}
+
+	client, err := services.NewDockerClient(config.Endpoint, config.TLSSkipVerify)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+	if err := client.Ping(c.Request.Context()); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	stats, err := fetchDockerStats(c.Request.Context(), config)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// DockerContainerAction starts, stops or restarts one of the widget's
+// containers. Widgets must opt in with allowActions.
+func DockerContainerAction(c *gin.Context) {
+	widget, ok := loadDockerWidget(c)
+	if !ok {
+		return
+	}
+	config := dockerConfigFromWidget(widget.Config)
+	if !config.AllowActions {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Container actions are disabled for this widget"})
+		return
+	}
+
+	action := c.Param("action")
+	if action != "start" && action != "stop" && action != "restart" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Action must be start, stop or restart"})
+		return
+	}
+
+	client, err := services.NewDockerClient(config.Endpoint, config.TLSSkipVerify)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	containers, err := client.ListContainers(c.Request.Context(), true)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	var target *services.DockerContainer
+	for i := range containers {
+		if containers[i].ID == c.Param("container_id") {
+			target = &containers[i]
+			break
+		}
+	}
+	if target == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+		return
+	}
+
+	if err := client.ContainerAction(c.Request.Context(), target.ID, action); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		return audit.Record(tx, c, models.AuditActionControl, audit.EntityWidget, widget.ID, nil, map[string]interface{}{
+			"action":    action,
+			"container": target.Name(),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": fmt.Sprintf("Container %s: %s done", target.Name(), action)})
+}
+
+func fetchDockerStats(ctx context.Context, config DockerConfig) (*DockerStats, error) {
+	client, err := services.NewDockerClient(config.Endpoint, config.TLSSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := client.ListContainers(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &DockerStats{
+		AllowActions: config.AllowActions,
+		Containers:   []DockerContainerSummary{},
+		Alerts:       []Alert{},
+	}
+
+	summaries := make([]DockerContainerSummary, len(containers))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, dockerStatsConcurrency)
+	for i := range containers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			summaries[i] = summarizeContainer(ctx, client, &containers[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, summary := range summaries {
+		stats.Total++
+		switch summary.State {
+		case "running":
+			stats.Running++
+		case "restarting":
+			stats.Restarting++
+			stats.Alerts = append(stats.Alerts, Alert{
+				Message: fmt.Sprintf("%s is restarting (%d restarts)", summary.Name, summary.RestartCount),
+				Level:   "warning",
+			})
+		default:
+			stats.Stopped++
+		}
+		if summary.Health == "unhealthy" {
+			stats.Unhealthy++
+			stats.Alerts = append(stats.Alerts, Alert{
+				Message: fmt.Sprintf("%s is unhealthy", summary.Name),
+				Level:   "error",
+			})
+		}
+
+		if summary.State == "running" || summary.State == "restarting" || config.ShowStopped {
+			stats.Containers = append(stats.Containers, summary)
+		}
+	}
+
+	sort.Slice(stats.Containers, func(i, j int) bool {
+		return stats.Containers[i].Name < stats.Containers[j].Name
+	})
+
+	return stats, nil
+}
+
+// summarizeContainer adds health, restarts and resource use to a listed
+// container. Failures leave those fields empty rather than failing the widget.
+func summarizeContainer(ctx context.Context, client *services.DockerClient, container *services.DockerContainer) DockerContainerSummary {
+	summary := DockerContainerSummary{
+		ID:     container.ID,
+		Name:   container.Name(),
+		Image:  container.Image,
+		State:  container.State,
+		Status: container.Status,
+	}
+
+	if inspect, err := client.InspectContainer(ctx, container.ID); err == nil {
+		summary.RestartCount = inspect.RestartCount
+		if inspect.State.Health != nil {
+			summary.Health = inspect.State.Health.Status
+		}
+	}
+
+	if container.State != "running" {
+		return summary
+	}
+	if usage, err := client.ContainerStats(ctx, container.ID); err == nil {
+		summary.CPUPercent = usage.CPUPercent()
+		summary.MemoryUsage = usage.MemoryUsage()
+		summary.MemoryLimit = usage.MemoryStats.Limit
+		if summary.MemoryLimit > 0 {
+			summary.MemoryPercent = float64(summary.MemoryUsage) / float64(summary.MemoryLimit) * 100
+		}
+	}
+
+	return summary
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dashboard-server/models"
+)
+
+// fakeDockerEngine serves a web container that is running but unhealthy, a
+// worker that keeps restarting and a stopped backup job.
+func fakeDockerEngine(t *testing.T) *httptest.Server {
+	t.Helper()
+	reply := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/json", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, []map[string]interface{}{
+			{"Id": "w1", "Names": []string{"/web"}, "Image": "nginx", "State": "running"},
+			{"Id": "q1", "Names": []string{"/worker"}, "Image": "worker", "State": "restarting"},
+			{"Id": "b1", "Names": []string{"/backup"}, "Image": "restic", "State": "exited"},
+		})
+	})
+	mux.HandleFunc("GET /containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("id") {
+		case "w1":
+			reply(w, map[string]interface{}{"State": map[string]interface{}{"Status": "running", "Health": map[string]string{"Status": "unhealthy"}}})
+		case "q1":
+			reply(w, map[string]interface{}{"RestartCount": 7, "State": map[string]interface{}{"Status": "restarting", "Restarting": true}})
+		default:
+			reply(w, map[string]interface{}{"State": map[string]interface{}{"Status": "exited"}})
+		}
+	})
+	mux.HandleFunc("GET /containers/w1/stats", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]interface{}{
+			"cpu_stats":    map[string]interface{}{"cpu_usage": map[string]interface{}{"total_usage": 200}, "system_cpu_usage": 2000, "online_cpus": 2},
+			"precpu_stats": map[string]interface{}{"cpu_usage": map[string]interface{}{"total_usage": 100}, "system_cpu_usage": 1000},
+			"memory_stats": map[string]interface{}{"usage": 256, "limit": 1024},
+		})
+	})
+	mux.HandleFunc("POST /containers/{id}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected container action %s on %s", r.PathValue("action"), r.PathValue("id"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchDockerStats(t *testing.T) {
+	engine := fakeDockerEngine(t)
+
+	stats, err := fetchDockerStats(context.Background(), DockerConfig{Endpoint: engine.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total != 3 || stats.Running != 1 || stats.Restarting != 1 || stats.Stopped != 1 || stats.Unhealthy != 1 {
+		t.Errorf("counts = %+v", stats)
+	}
+
+	// Stopped containers are hidden unless showStopped is set.
+	if len(stats.Containers) != 2 || stats.Containers[0].Name != "web" || stats.Containers[1].Name != "worker" {
+		t.Fatalf("containers = %+v", stats.Containers)
+	}
+	web := stats.Containers[0]
+	if web.Health != "unhealthy" || web.CPUPercent != 20 || web.MemoryUsage != 256 || web.MemoryPercent != 25 {
+		t.Errorf("web = %+v", web)
+	}
+	if worker := stats.Containers[1]; worker.RestartCount != 7 || worker.CPUPercent != 0 {
+		t.Errorf("worker = %+v", worker)
+	}
+
+	alerts := map[string]string{}
+	for _, alert := range stats.Alerts {
+		alerts[alert.Message] = alert.Level
+	}
+	want := map[string]string{
+		"web is unhealthy":                  "error",
+		"worker is restarting (7 restarts)": "warning",
+	}
+	if len(alerts) != len(want) {
+		t.Errorf("alerts = %v, want %v", alerts, want)
+	}
+	for message, level := range want {
+		if alerts[message] != level {
+			t.Errorf("alert %q level = %q, want %q", message, alerts[message], level)
+		}
+	}
+
+	stats, err = fetchDockerStats(context.Background(), DockerConfig{Endpoint: engine.URL, ShowStopped: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Containers) != 3 || stats.Containers[0].Name != "backup" {
+		t.Errorf("containers with showStopped = %+v", stats.Containers)
+	}
+}
+
+func TestDockerContainerActionDisabled(t *testing.T) {
+	dashboard := setupDB(t)
+	engine := fakeDockerEngine(t)
+	widget := createTestWidget(t, models.Widget{
+		DashboardID: dashboard.ID,
+		Name:        "Docker",
+		Type:        "docker",
+		Config:      models.JSON{"endpoint": engine.URL},
+	})
+
+	route := "/docker/:widget_id/containers/:container_id/:action"
+	var response map[string]interface{}
+	w := serve(t, http.MethodPost, route, fmt.Sprintf("/docker/%d/containers/w1/restart", widget.ID), nil, &response, DockerContainerAction)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403: %s", w.Code, w.Body)
+	}
+	if response["error"] == nil {
+		t.Errorf("response = %v", response)
+	}
+}
+
+func TestDockerSocketNotAllowed(t *testing.T) {
+	t.Setenv("DOCKER_SOCKETS", "/var/run/docker.sock")
+	_, err := fetchDockerStats(context.Background(), DockerConfig{Endpoint: "unix:///home/user/docker.sock"})
+	if err == nil {
+		t.Fatal("fetchDockerStats used a socket missing from DOCKER_SOCKETS")
+	}
+}
@@ -0,0 +1,309 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"dashboard-server/agent"
+	"dashboard-server/audit"
+	"dashboard-server/database"
+	"dashboard-server/glances"
+	"dashboard-server/metrics"
+	"dashboard-server/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type HostRequest struct {
+	Name     *string `json:"name"`
+	Type     *string `json:"type"`
+	URL      *string `json:"url"`
+	Username *string `json:"username"`
+	Password *string `json:"password"`
+	Enabled  *bool   `json:"enabled"`
+}
+
+// HostStats is one host's entry in the all-hosts response. A host that
+// cannot be reached reports its error instead of failing the whole request.
+type HostStats struct {
+	HostID uint           `json:"host_id"`
+	Name   string         `json:"name"`
+	Data   *glances.Stats `json:"data,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+func (r *HostRequest) apply(host *models.MonitoredHost) {
+	if r.Name != nil {
+		host.Name = *r.Name
+	}
+	if r.Type != nil {
+		host.Type = *r.Type
+	}
+	if r.URL != nil {
+		host.URL = *r.URL
+	}
+	if r.Username != nil {
+		host.Username = *r.Username
+	}
+	if r.Password != nil {
+		host.Password = *r.Password
+	}
+	if r.Enabled != nil {
+		host.Enabled = *r.Enabled
+	}
+}
+
+func validateHost(host *models.MonitoredHost) string {
+	if host.Name == "" {
+		return "Host name is required"
+	}
+	parsed, err := url.Parse(host.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "Host URL must be an http or https URL"
+	}
+	switch host.Type {
+	case models.HostTypeGlances:
+	case models.HostTypeAgent:
+		if host.Password == "" {
+			return "Agent hosts need the agent token as password"
+		}
+	default:
+		return "Host type must be glances or agent"
+	}
+	return ""
+}
+
+func fetchHostStats(ctx context.Context, host *models.MonitoredHost) (stats *glances.Stats, err error) {
+	defer func(start time.Time) { metrics.ObserveFetch(host.Type, start, err) }(time.Now())
+
+	if host.Type == models.HostTypeAgent {
+		return agent.FetchStats(ctx, host.URL, host.Password)
+	}
+	return glances.Fetch(ctx, glances.HostConfig(host))
+}
+
+func fetchHostDetails(ctx context.Context, host *models.MonitoredHost) (details *glances.Details, err error) {
+	defer func(start time.Time) { metrics.ObserveFetch(host.Type, start, err) }(time.Now())
+
+	if host.Type == models.HostTypeAgent {
+		return agent.FetchDetails(ctx, host.URL, host.Password)
+	}
+	return glances.FetchDetails(ctx, glances.HostConfig(host))
+}
+
+func testHost(ctx context.Context, host *models.MonitoredHost) error {
+	if host.Type == models.HostTypeAgent {
+		return agent.Test(ctx, host.URL, host.Password)
+	}
+	return glances.Test(ctx, glances.HostConfig(host))
+}
+
+func GetHosts(c *gin.Context) {
+	var hosts []models.MonitoredHost
+	if err := database.DB.Order("name").Find(&hosts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	responses := []models.MonitoredHostResponse{}
+	for _, host := range hosts {
+		responses = append(responses, host.ToResponse())
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+func GetHost(c *gin.Context) {
+	var host models.MonitoredHost
+	if err := database.DB.First(&host, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Host not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": host.ToResponse()})
+}
+
+func CreateHost(c *gin.Context) {
+	var request HostRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	host := models.MonitoredHost{Type: models.HostTypeGlances, Enabled: true}
+	request.apply(&host)
+	if message := validateHost(&host); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&host).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, models.AuditActionCreate, audit.EntityHost, host.ID, nil, audit.HostFields(&host))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": host.ToResponse()})
+}
+
+func UpdateHost(c *gin.Context) {
+	var host models.MonitoredHost
+	if err := database.DB.First(&host, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Host not found"})
+		return
+	}
+	before := audit.HostFields(&host)
+
+	var request HostRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	request.apply(&host)
+	if message := validateHost(&host); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&host).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, models.AuditActionUpdate, audit.EntityHost, host.ID, before, audit.HostFields(&host))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": host.ToResponse()})
+}
+
+func DeleteHost(c *gin.Context) {
+	var host models.MonitoredHost
+	if err := database.DB.First(&host, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Host not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&host).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, models.AuditActionDelete, audit.EntityHost, host.ID, audit.HostFields(&host), nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Host deleted successfully"})
+}
+
+func GetHostStats(c *gin.Context) {
+	var host models.MonitoredHost
+	if err := database.DB.First(&host, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Host not found"})
+		return
+	}
+
+	stats, err := fetchHostStats(c.Request.Context(), &host)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch host data: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
+
+func GetHostDetails(c *gin.Context) {
+	var host models.MonitoredHost
+	if err := database.DB.First(&host, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Host not found"})
+		return
+	}
+
+	details, err := fetchHostDetails(c.Request.Context(), &host)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch host data: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": details})
+}
+
+// GetAllHostStats fetches every enabled host in parallel.
+func GetAllHostStats(c *gin.Context) {
+	var hosts []models.MonitoredHost
+	if err := database.DB.Where("enabled = ?", true).Order("name").Find(&hosts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	results := make([]HostStats, len(hosts))
+	var wg sync.WaitGroup
+	for i := range hosts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = HostStats{HostID: hosts[i].ID, Name: hosts[i].Name}
+			stats, err := fetchHostStats(ctx, &hosts[i])
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Data = stats
+		}(i)
+	}
+	wg.Wait()
+
+	c.JSON(http.StatusOK, gin.H{"data": results})
+}
+
+func TestHostConnection(c *gin.Context) {
+	var host models.MonitoredHost
+	if err := database.DB.First(&host, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Host not found"})
+		return
+	}
+
+	if err := testHost(c.Request.Context(), &host); err != nil {
+		c.JSON(http.StatusOK, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Connection successful"})
+}
+
+// TestNewHost checks connection settings before they are saved.
+func TestNewHost(c *gin.Context) {
+	var request HostRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	host := models.MonitoredHost{Name: "test", Type: models.HostTypeGlances}
+	request.apply(&host)
+	if message := validateHost(&host); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return
+	}
+
+	if err := testHost(c.Request.Context(), &host); err != nil {
+		c.JSON(http.StatusOK, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Connection successful"})
+}
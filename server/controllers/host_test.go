@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+
+	"dashboard-server/database"
+	"dashboard-server/models"
+)
+
+func TestCreateDisabledHost(t *testing.T) {
+	setupDB(t)
+
+	var response struct {
+		Data models.MonitoredHostResponse `json:"data"`
+	}
+	body := map[string]interface{}{"name": "nas", "url": "http://nas:61208", "enabled": false}
+	w := serve(t, http.MethodPost, "/hosts", "/hosts", body, &response, CreateHost)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if response.Data.Enabled {
+		t.Error("response reports the host as enabled")
+	}
+
+	var saved models.MonitoredHost
+	database.DB.First(&saved, response.Data.ID)
+	if saved.Enabled {
+		t.Error("host was stored as enabled")
+	}
+}
+
+func TestCreateHostEnabledByDefault(t *testing.T) {
+	setupDB(t)
+
+	var response struct {
+		Data models.MonitoredHostResponse `json:"data"`
+	}
+	body := map[string]interface{}{"name": "nas", "url": "http://nas:61208"}
+	if w := serve(t, http.MethodPost, "/hosts", "/hosts", body, &response, CreateHost); w.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+
+	var saved models.MonitoredHost
+	database.DB.First(&saved, response.Data.ID)
+	if !saved.Enabled {
+		t.Error("host without enabled was stored as disabled")
+	}
+}
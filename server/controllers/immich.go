@@ -1,16 +1,19 @@
 package controllers
 
 import (
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	"dashboard-server/database"
+	"dashboard-server/metrics"
 	"dashboard-server/models"
+	"dashboard-server/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -64,7 +67,7 @@ type ImmichStats struct {
 func TestImmichConnection(c *gin.Context) {
 	var config ImmichTestConfig
 	if err := c.ShouldBindJSON(&config); err != nil {
-		fmt.Println("Error binding JSON:", err)
+		slog.DebugContext(c.Request.Context(), "Invalid test configuration", "integration", "immich", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration: " + err.Error()})
 		return
 	}
@@ -79,7 +82,7 @@ func TestImmichConnection(c *gin.Context) {
 		return
 	}
 
-	stats, err := fetchImmichStats(config.ServerURL, config.ApiKey)
+	stats, err := fetchImmichStats(c.Request.Context(), config.ServerURL, config.ApiKey)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
@@ -120,26 +123,31 @@ func ProxyImmichStats(c *gin.Context) {
 		return
 	}
 
-	stats, err := fetchImmichStats(serverURL, apiKey)
+	start := time.Now()
+	stats, err := fetchImmichStats(c.Request.Context(), serverURL, apiKey)
+	metrics.ObserveFetch("immich", start, err)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
 	}
 
+	metrics.SetWidgetValues(widget.ID, widget.Name, widget.Type, map[string]float64{
+		"photos":      float64(stats.ServerStats.Photos),
+		"videos":      float64(stats.ServerStats.Videos),
+		"usage_bytes": float64(stats.ServerStats.Usage),
+		"users":       float64(stats.Users),
+		"alerts":      float64(len(stats.Alerts)),
+	})
+
 	c.JSON(http.StatusOK, stats)
 }
 
-func fetchImmichStats(serverURL, apiKey string) (*ImmichStats, error) {
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-	}
+func fetchImmichStats(ctx context.Context, serverURL, apiKey string) (*ImmichStats, error) {
+	client := services.NewHTTPClient(30*time.Second, true)
 
 	stats := &ImmichStats{}
 
-	serverStats, err := fetchImmichServerStatistics(client, serverURL, apiKey)
+	serverStats, err := fetchImmichServerStatistics(ctx, client, serverURL, apiKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch server statistics: %v", err)
 	}
@@ -147,23 +155,23 @@ func fetchImmichStats(serverURL, apiKey string) (*ImmichStats, error) {
 	stats.Users = int64(len(serverStats.UsageByUser))
 	stats.ServerStats = *serverStats
 
-	storage, err := fetchImmichStorage(client, serverURL, apiKey)
+	storage, err := fetchImmichStorage(ctx, client, serverURL, apiKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch storage: %v", err)
 	}
 	stats.Storage = *storage
 
-	about, err := fetchImmichAbout(client, serverURL, apiKey)
+	about, err := fetchImmichAbout(ctx, client, serverURL, apiKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch about info: %v", err)
 	}
 
 	var alerts = make([]Alert, 0)
 
-	notificationCount, err := fetchImmichNotifications(client, serverURL, apiKey)
+	notificationCount, err := fetchImmichNotifications(ctx, client, serverURL, apiKey)
 
 	if err != nil {
-		fmt.Printf("Warning: failed to fetch notifications: %v\n", err)
+		slog.WarnContext(ctx, "Failed to fetch Immich notifications", "error", err)
 	} else if notificationCount > 0 {
 		alerts = append(alerts, Alert{
 			Message: fmt.Sprintf("You have %d unread notifications", notificationCount),
@@ -171,9 +179,9 @@ func fetchImmichStats(serverURL, apiKey string) (*ImmichStats, error) {
 		})
 	}
 
-	versionCheck, err := fetchImmichVersionCheck(client, serverURL, apiKey)
+	versionCheck, err := fetchImmichVersionCheck(ctx, client, serverURL, apiKey)
 	if err != nil {
-		fmt.Printf("Warning: failed to fetch version check: %v\n", err)
+		slog.WarnContext(ctx, "Failed to fetch Immich version check", "error", err)
 	} else {
 		if versionCheck.ReleaseVersion != about.Version {
 			message := fmt.Sprintf("A new Immich version %s is available! You are running version %s.", versionCheck.ReleaseVersion, about.Version)
@@ -189,10 +197,10 @@ func fetchImmichStats(serverURL, apiKey string) (*ImmichStats, error) {
 	return stats, nil
 }
 
-func fetchImmichServerStatistics(client *http.Client, serverURL, apiKey string) (*ImmichServerStatistics, error) {
+func fetchImmichServerStatistics(ctx context.Context, client *http.Client, serverURL, apiKey string) (*ImmichServerStatistics, error) {
 	url := fmt.Sprintf("%s/api/server/statistics", serverURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -219,10 +227,10 @@ func fetchImmichServerStatistics(client *http.Client, serverURL, apiKey string)
 	return &stats, nil
 }
 
-func fetchImmichStorage(client *http.Client, serverURL, apiKey string) (*ImmichStorage, error) {
+func fetchImmichStorage(ctx context.Context, client *http.Client, serverURL, apiKey string) (*ImmichStorage, error) {
 	url := fmt.Sprintf("%s/api/server/storage", serverURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -249,10 +257,10 @@ func fetchImmichStorage(client *http.Client, serverURL, apiKey string) (*ImmichS
 	return &storage, nil
 }
 
-func fetchImmichAbout(client *http.Client, serverURL, apiKey string) (*ImmichAboutInfo, error) {
+func fetchImmichAbout(ctx context.Context, client *http.Client, serverURL, apiKey string) (*ImmichAboutInfo, error) {
 	url := fmt.Sprintf("%s/api/server/about", serverURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -279,10 +287,10 @@ func fetchImmichAbout(client *http.Client, serverURL, apiKey string) (*ImmichAbo
 	return &about, nil
 }
 
-func fetchImmichNotifications(client *http.Client, serverURL, apiKey string) (int64, error) {
+func fetchImmichNotifications(ctx context.Context, client *http.Client, serverURL, apiKey string) (int64, error) {
 	url := fmt.Sprintf("%s/api/notifications", serverURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -309,10 +317,10 @@ func fetchImmichNotifications(client *http.Client, serverURL, apiKey string) (in
 	return int64(len(notificationsArray)), nil
 }
 
-func fetchImmichVersionCheck(client *http.Client, serverURL, apiKey string) (*ImmichVersionCheck, error) {
+func fetchImmichVersionCheck(ctx context.Context, client *http.Client, serverURL, apiKey string) (*ImmichVersionCheck, error) {
 	url := fmt.Sprintf("%s/api/server/version-check", serverURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
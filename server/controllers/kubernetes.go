@@ -0,0 +1,319 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"dashboard-server/database"
+	"dashboard-server/metrics"
+	"dashboard-server/models"
+	"dashboard-server/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KubernetesConfig struct {
+	Context     string  `json:"context"`
+	Namespaces  string  `json:"namespaces"`
+	EventWindow float64 `json:"eventWindow"`
+}
+
+type KubernetesNodeSummary struct {
+	Ready    int      `json:"ready"`
+	NotReady int      `json:"notReady"`
+	Total    int      `json:"total"`
+	Down     []string `json:"down"`
+}
+
+type KubernetesNamespacePods struct {
+	Namespace string `json:"namespace,omitempty"`
+	Running   int    `json:"running"`
+	Pending   int    `json:"pending"`
+	Failed    int    `json:"failed"`
+	Succeeded int    `json:"succeeded"`
+	Total     int    `json:"total"`
+}
+
+type KubernetesPodIssue struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Restarts  int    `json:"restarts"`
+}
+
+type KubernetesPVCIssue struct {
+	Namespace    string    `json:"namespace"`
+	Name         string    `json:"name"`
+	StorageClass string    `json:"storageClass"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type KubernetesWarning struct {
+	Namespace string    `json:"namespace"`
+	Object    string    `json:"object"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Count     int       `json:"count"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+type KubernetesStats struct {
+	Nodes        KubernetesNodeSummary     `json:"nodes"`
+	Pods         KubernetesNamespacePods   `json:"pods"`
+	Namespaces   []KubernetesNamespacePods `json:"namespaces"`
+	CrashLooping []KubernetesPodIssue      `json:"crashLooping"`
+	PendingPVCs  []KubernetesPVCIssue      `json:"pendingPVCs"`
+	Events       []KubernetesWarning       `json:"events"`
+	Alerts       []Alert                   `json:"alerts"`
+}
+
+const (
+	defaultKubernetesEventWindow = time.Hour
+	maxKubernetesEvents          = 10
+)
+
+func kubernetesConfigFromWidget(config models.JSON) KubernetesConfig {
+	kubernetesConfig := KubernetesConfig{}
+	if kubeContext, ok := config["context"].(string); ok {
+		kubernetesConfig.Context = kubeContext
+	}
+	if namespaces, ok := config["namespaces"].(string); ok {
+		kubernetesConfig.Namespaces = namespaces
+	}
+	if window, ok := config["eventWindow"].(float64); ok {
+		kubernetesConfig.EventWindow = window
+	}
+	return kubernetesConfig
+}
+
+// namespaces splits the comma-separated filter; empty means all.
+func (k KubernetesConfig) namespaces() []string {
+	var namespaces []string
+	for _, namespace := range strings.Split(k.Namespaces, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+func (k KubernetesConfig) eventWindow() time.Duration {
+	if k.EventWindow > 0 {
+		return time.Duration(k.EventWindow * float64(time.Minute))
+	}
+	return defaultKubernetesEventWindow
+}
+
+func ProxyKubernetesStats(c *gin.Context) {
+	widgetID, err := strconv.ParseUint(c.Param("widget_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid widget ID"})
+		return
+	}
+
+	var widget models.Widget
+	if err := database.DB.First(&widget, uint(widgetID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Widget not found"})
+		return
+	}
+
+	if widget.Type != "kubernetes" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Widget is not a Kubernetes widget"})
+		return
+	}
+
+	config := kubernetesConfigFromWidget(widget.Config)
+
+	start := time.Now()
+	stats, err := fetchKubernetesStats(c.Request.Context(), config)
+	metrics.ObserveFetch("kubernetes", start, err)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("Failed to fetch Kubernetes stats: %v", err),
+		})
+		return
+	}
+
+	metrics.SetWidgetValues(widget.ID, widget.Name, widget.Type, map[string]float64{
+		"ready_nodes":     float64(stats.Nodes.Ready),
+		"not_ready_nodes": float64(stats.Nodes.NotReady),
+		"running_pods":    float64(stats.Pods.Running),
+		"pending_pods":    float64(stats.Pods.Pending),
+		"failed_pods":     float64(stats.Pods.Failed),
+		"crashloop_pods":  float64(len(stats.CrashLooping)),
+		"pending_pvcs":    float64(len(stats.PendingPVCs)),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+func TestKubernetesConnection(c *gin.Context) {
+	var config KubernetesConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration", "details": err.Error()})
+		return
+	}
+
+	stats, err := fetchKubernetesStats(c.Request.Context(), config)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"success": false, "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+func fetchKubernetesStats(ctx context.Context, config KubernetesConfig) (*KubernetesStats, error) {
+	client, err := services.NewKubernetesClient(config.Context)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	namespaces := config.namespaces()
+
+	nodes, err := client.Nodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := client.Pods(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := client.PersistentVolumeClaims(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	events, err := client.WarningEvents(ctx, namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &KubernetesStats{
+		Nodes:        KubernetesNodeSummary{Down: []string{}},
+		Namespaces:   []KubernetesNamespacePods{},
+		CrashLooping: []KubernetesPodIssue{},
+		PendingPVCs:  []KubernetesPVCIssue{},
+		Events:       []KubernetesWarning{},
+		Alerts:       []Alert{},
+	}
+
+	for i := range nodes {
+		stats.Nodes.Total++
+		if nodes[i].Ready() {
+			stats.Nodes.Ready++
+			continue
+		}
+		stats.Nodes.NotReady++
+		stats.Nodes.Down = append(stats.Nodes.Down, nodes[i].Metadata.Name)
+		stats.Alerts = append(stats.Alerts, Alert{
+			Message: fmt.Sprintf("Node %s is not ready", nodes[i].Metadata.Name),
+			Level:   "error",
+		})
+	}
+
+	byNamespace := make(map[string]*KubernetesNamespacePods)
+	for i := range pods {
+		pod := &pods[i]
+		counts := byNamespace[pod.Metadata.Namespace]
+		if counts == nil {
+			counts = &KubernetesNamespacePods{Namespace: pod.Metadata.Namespace}
+			byNamespace[pod.Metadata.Namespace] = counts
+		}
+		for _, totals := range []*KubernetesNamespacePods{counts, &stats.Pods} {
+			totals.Total++
+			switch pod.Status.Phase {
+			case "Running":
+				totals.Running++
+			case "Pending":
+				totals.Pending++
+			case "Failed":
+				totals.Failed++
+			case "Succeeded":
+				totals.Succeeded++
+			}
+		}
+
+		for _, container := range pod.Status.ContainerStatuses {
+			if container.State.Waiting == nil || container.State.Waiting.Reason != "CrashLoopBackOff" {
+				continue
+			}
+			stats.CrashLooping = append(stats.CrashLooping, KubernetesPodIssue{
+				Namespace: pod.Metadata.Namespace,
+				Pod:       pod.Metadata.Name,
+				Container: container.Name,
+				Restarts:  container.RestartCount,
+			})
+			stats.Alerts = append(stats.Alerts, Alert{
+				Message: fmt.Sprintf("%s/%s is crash looping (%d restarts)", pod.Metadata.Namespace, pod.Metadata.Name, container.RestartCount),
+				Level:   "warning",
+			})
+		}
+	}
+	for _, counts := range byNamespace {
+		stats.Namespaces = append(stats.Namespaces, *counts)
+	}
+	sort.Slice(stats.Namespaces, func(i, j int) bool {
+		return stats.Namespaces[i].Namespace < stats.Namespaces[j].Namespace
+	})
+
+	for i := range claims {
+		claim := &claims[i]
+		if claim.Status.Phase != "Pending" {
+			continue
+		}
+		issue := KubernetesPVCIssue{
+			Namespace: claim.Metadata.Namespace,
+			Name:      claim.Metadata.Name,
+			CreatedAt: claim.Metadata.CreationTimestamp,
+		}
+		if claim.Spec.StorageClassName != nil {
+			issue.StorageClass = *claim.Spec.StorageClassName
+		}
+		stats.PendingPVCs = append(stats.PendingPVCs, issue)
+		stats.Alerts = append(stats.Alerts, Alert{
+			Message: fmt.Sprintf("Volume claim %s/%s is pending", issue.Namespace, issue.Name),
+			Level:   "warning",
+		})
+	}
+
+	since := time.Now().Add(-config.eventWindow())
+	for i := range events {
+		event := &events[i]
+		lastSeen := event.LastSeen()
+		if lastSeen.Before(since) {
+			continue
+		}
+		count := event.Count
+		if count == 0 {
+			count = 1
+		}
+		stats.Events = append(stats.Events, KubernetesWarning{
+			Namespace: event.Metadata.Namespace,
+			Object:    strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name,
+			Reason:    event.Reason,
+			Message:   event.Message,
+			Count:     count,
+			LastSeen:  lastSeen,
+		})
+	}
+	sort.Slice(stats.Events, func(i, j int) bool {
+		return stats.Events[i].LastSeen.After(stats.Events[j].LastSeen)
+	})
+	if len(stats.Events) > maxKubernetesEvents {
+		stats.Events = stats.Events[:maxKubernetesEvents]
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeKubernetesAPI serves lists from resources, keyed by request path, to
+// clients presenting the bearer token "test-token". KUBECONFIG is pointed
+// at it for the rest of the test.
+func fakeKubernetesAPI(t *testing.T, resources map[string][]interface{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"message": "Unauthorized"})
+			return
+		}
+		if r.URL.Path == "/api/v1/events" || filepath.Base(r.URL.Path) == "events" {
+			if r.URL.Query().Get("fieldSelector") != "type=Warning" {
+				t.Errorf("events listed without the warning selector: %s", r.URL)
+			}
+		}
+		items, ok := resources[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"message": "the server could not find the requested resource"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+	t.Cleanup(server.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: homelab
+clusters:
+- name: homelab
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+contexts:
+- name: homelab
+  context:
+    cluster: homelab
+    user: dashboard
+users:
+- name: dashboard
+  user:
+    token: test-token
+`, server.URL, base64.StdEncoding.EncodeToString(ca))
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", path)
+	return server
+}
+
+func kubernetesObject(namespace, name string) map[string]interface{} {
+	return map[string]interface{}{"namespace": namespace, "name": name, "creationTimestamp": "2026-01-01T00:00:00Z"}
+}
+
+func TestFetchKubernetesStats(t *testing.T) {
+	recent := time.Now().Add(-10 * time.Minute).UTC().Format(time.RFC3339)
+	old := time.Now().Add(-3 * time.Hour).UTC().Format(time.RFC3339)
+
+	fakeKubernetesAPI(t, map[string][]interface{}{
+		"/api/v1/nodes": {
+			map[string]interface{}{"metadata": kubernetesObject("", "node-a"), "status": map[string]interface{}{
+				"conditions": []map[string]string{{"type": "MemoryPressure", "status": "False"}, {"type": "Ready", "status": "True"}},
+			}},
+			map[string]interface{}{"metadata": kubernetesObject("", "node-b"), "status": map[string]interface{}{
+				"conditions": []map[string]string{{"type": "Ready", "status": "Unknown"}},
+			}},
+			map[string]interface{}{"metadata": kubernetesObject("", "node-c")},
+		},
+		"/api/v1/pods": {
+			map[string]interface{}{"metadata": kubernetesObject("media", "sonarr-0"), "status": map[string]interface{}{"phase": "Running"}},
+			map[string]interface{}{"metadata": kubernetesObject("media", "radarr-0"), "status": map[string]interface{}{
+				"phase": "Running",
+				"containerStatuses": []map[string]interface{}{
+					{"name": "radarr", "restartCount": 12, "state": map[string]interface{}{"waiting": map[string]string{"reason": "CrashLoopBackOff"}}},
+					{"name": "exporter", "restartCount": 0, "state": map[string]interface{}{"waiting": map[string]string{"reason": "ContainerCreating"}}},
+				},
+			}},
+			map[string]interface{}{"metadata": kubernetesObject("kube-system", "job-1"), "status": map[string]interface{}{"phase": "Succeeded"}},
+			map[string]interface{}{"metadata": kubernetesObject("kube-system", "dns-1"), "status": map[string]interface{}{"phase": "Pending"}},
+		},
+		"/api/v1/persistentvolumeclaims": {
+			map[string]interface{}{"metadata": kubernetesObject("media", "config"), "spec": map[string]interface{}{"storageClassName": "longhorn"}, "status": map[string]string{"phase": "Pending"}},
+			map[string]interface{}{"metadata": kubernetesObject("media", "data"), "status": map[string]string{"phase": "Bound"}},
+		},
+		"/api/v1/events": {
+			map[string]interface{}{"metadata": kubernetesObject("media", "e1"), "type": "Warning", "reason": "BackOff", "message": "Back-off restarting failed container",
+				"count": 5, "lastTimestamp": recent, "involvedObject": map[string]string{"kind": "Pod", "name": "radarr-0"}},
+			map[string]interface{}{"metadata": kubernetesObject("media", "e2"), "type": "Warning", "reason": "FailedMount",
+				"eventTime": recent, "involvedObject": map[string]string{"kind": "Pod", "name": "sonarr-0"}},
+			map[string]interface{}{"metadata": kubernetesObject("media", "e3"), "type": "Warning", "reason": "Evicted",
+				"lastTimestamp": old, "involvedObject": map[string]string{"kind": "Pod", "name": "old-0"}},
+		},
+	})
+
+	stats, err := fetchKubernetesStats(context.Background(), KubernetesConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Nodes.Total != 3 || stats.Nodes.Ready != 1 || stats.Nodes.NotReady != 2 {
+		t.Errorf("nodes = %+v", stats.Nodes)
+	}
+	if len(stats.Nodes.Down) != 2 || stats.Nodes.Down[0] != "node-b" || stats.Nodes.Down[1] != "node-c" {
+		t.Errorf("down nodes = %v", stats.Nodes.Down)
+	}
+
+	if stats.Pods.Total != 4 || stats.Pods.Running != 2 || stats.Pods.Pending != 1 || stats.Pods.Succeeded != 1 {
+		t.Errorf("pods = %+v", stats.Pods)
+	}
+	if len(stats.Namespaces) != 2 || stats.Namespaces[0].Namespace != "kube-system" || stats.Namespaces[1].Running != 2 {
+		t.Errorf("namespaces = %+v", stats.Namespaces)
+	}
+
+	if len(stats.CrashLooping) != 1 {
+		t.Fatalf("crash looping = %+v", stats.CrashLooping)
+	}
+	if issue := stats.CrashLooping[0]; issue.Pod != "radarr-0" || issue.Container != "radarr" || issue.Restarts != 12 {
+		t.Errorf("crash looping = %+v", issue)
+	}
+
+	if len(stats.PendingPVCs) != 1 || stats.PendingPVCs[0].Name != "config" || stats.PendingPVCs[0].StorageClass != "longhorn" {
+		t.Errorf("pending PVCs = %+v", stats.PendingPVCs)
+	}
+
+	// The evicted pod's event is older than the default one-hour window.
+	if len(stats.Events) != 2 {
+		t.Fatalf("events = %+v", stats.Events)
+	}
+	for _, event := range stats.Events {
+		switch event.Reason {
+		case "BackOff":
+			if event.Object != "pod/radarr-0" || event.Count != 5 {
+				t.Errorf("BackOff event = %+v", event)
+			}
+		case "FailedMount":
+			if event.Count != 1 {
+				t.Errorf("FailedMount count = %d, want 1", event.Count)
+			}
+		default:
+			t.Errorf("unexpected event %+v", event)
+		}
+	}
+
+	alerts := map[string]string{}
+	for _, alert := range stats.Alerts {
+		alerts[alert.Message] = alert.Level
+	}
+	want := map[string]string{
+		"Node node-b is not ready":                      "error",
+		"Node node-c is not ready":                      "error",
+		"media/radarr-0 is crash looping (12 restarts)": "warning",
+		"Volume claim media/config is pending":          "warning",
+	}
+	if len(alerts) != len(want) {
+		t.Errorf("alerts = %v, want %v", alerts, want)
+	}
+	for message, level := range want {
+		if alerts[message] != level {
+			t.Errorf("alert %q level = %q, want %q", message, alerts[message], level)
+		}
+	}
+
+	stats, err = fetchKubernetesStats(context.Background(), KubernetesConfig{EventWindow: 240})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Events) != 3 {
+		t.Errorf("events in a four-hour window = %+v", stats.Events)
+	}
+}
+
+func TestFetchKubernetesStatsNamespaces(t *testing.T) {
+	fakeKubernetesAPI(t, map[string][]interface{}{
+		"/api/v1/nodes":                                   {},
+		"/api/v1/namespaces/media/pods":                   {map[string]interface{}{"metadata": kubernetesObject("media", "sonarr-0"), "status": map[string]string{"phase": "Running"}}},
+		"/api/v1/namespaces/media/persistentvolumeclaims": {},
+		"/api/v1/namespaces/media/events":                 {},
+		"/api/v1/namespaces/home/pods":                    {map[string]interface{}{"metadata": kubernetesObject("home", "hass-0"), "status": map[string]string{"phase": "Running"}}},
+		"/api/v1/namespaces/home/persistentvolumeclaims":  {},
+		"/api/v1/namespaces/home/events":                  {},
+	})
+
+	stats, err := fetchKubernetesStats(context.Background(), KubernetesConfig{Namespaces: "media, home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Pods.Running != 2 || len(stats.Namespaces) != 2 {
+		t.Errorf("pods = %+v, namespaces = %+v", stats.Pods, stats.Namespaces)
+	}
+}
+
+func TestFetchKubernetesStatsUnauthorized(t *testing.T) {
+	fakeKubernetesAPI(t, map[string][]interface{}{})
+	path := os.Getenv("KUBECONFIG")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = bytes.Replace(data, []byte("token: test-token"), []byte("token: wrong-token"), 1)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fetchKubernetesStats(context.Background(), KubernetesConfig{})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 401") {
+		t.Errorf("error = %v, want HTTP 401", err)
+	}
+}
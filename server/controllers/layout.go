@@ -0,0 +1,434 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"dashboard-server/audit"
+	"dashboard-server/database"
+	"dashboard-server/layout"
+	"dashboard-server/models"
+	"dashboard-server/revisions"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type LayoutResponse struct {
+	Columns  models.LayoutColumns   `json:"columns"`
+	Sections []models.Section       `json:"sections"`
+	Widgets  []WidgetLayoutResponse `json:"widgets"`
+}
+
+type WidgetLayoutResponse struct {
+	ID        uint                `json:"id"`
+	SectionID *uint               `json:"section_id"`
+	Position  int                 `json:"position"`
+	Layout    models.WidgetLayout `json:"layout"`
+}
+
+type UpdateLayoutRequest struct {
+	Columns  models.LayoutColumns `json:"columns"`
+	Sections []SectionUpdate      `json:"sections"`
+	Widgets  []WidgetLayoutUpdate `json:"widgets"`
+}
+
+type SectionUpdate struct {
+	ID        uint    `json:"id" binding:"required"`
+	Title     *string `json:"title"`
+	Position  *int    `json:"position"`
+	Collapsed *bool   `json:"collapsed"`
+}
+
+type WidgetLayoutUpdate struct {
+	ID        uint                `json:"id" binding:"required"`
+	SectionID *uint               `json:"section_id"`
+	Position  *int                `json:"position"`
+	Layout    models.WidgetLayout `json:"layout"`
+}
+
+type SectionRequest struct {
+	Title     *string `json:"title"`
+	Position  *int    `json:"position"`
+	Collapsed *bool   `json:"collapsed"`
+}
+
+type layoutConflictError struct {
+	conflicts []layout.Conflict
+}
+
+func (e *layoutConflictError) Error() string {
+	return fmt.Sprintf("layout is invalid: %s", e.conflicts[0])
+}
+
+var errLayoutRequest = errors.New("invalid layout request")
+
+// fitWidget wraps layout.Fit so conflicts abort the surrounding transaction.
+func fitWidget(tx *gorm.DB, w *models.Widget) error {
+	conflicts, err := layout.Fit(tx, w)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &layoutConflictError{conflicts: conflicts}
+	}
+	return nil
+}
+
+// respondLayoutError writes the response for layout validation failures and
+// reports whether err was one.
+func respondLayoutError(c *gin.Context, err error) bool {
+	var conflictErr *layoutConflictError
+	switch {
+	case errors.As(err, &conflictErr):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "conflicts": conflictErr.conflicts})
+	case errors.Is(err, layout.ErrUnknownSection), errors.Is(err, layout.ErrUnknownBreakpoint),
+		errors.Is(err, layout.ErrInvalidColumns), errors.Is(err, errLayoutRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
+
+// widgetPlacement summarises the fields that decide where a widget sits, so
+// updates that leave them alone skip layout validation.
+func widgetPlacement(w *models.Widget) string {
+	data, _ := json.Marshal(struct {
+		DashboardID uint
+		SectionID   *uint
+		Layout      models.WidgetLayout
+	}{w.DashboardID, w.SectionID, w.Layout})
+	return string(data)
+}
+
+func GetDashboardLayout(c *gin.Context) {
+	id := c.Param("id")
+
+	var dashboard models.Dashboard
+	result := database.DB.Preload("Sections", orderByPosition).Preload("Widgets", orderByPosition).First(&dashboard, id)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dashboard not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": newLayoutResponse(&dashboard)})
+}
+
+func orderByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
+
+func newLayoutResponse(dashboard *models.Dashboard) LayoutResponse {
+	response := LayoutResponse{
+		Columns:  dashboard.Columns.Resolved(),
+		Sections: dashboard.Sections,
+		Widgets:  make([]WidgetLayoutResponse, 0, len(dashboard.Widgets)),
+	}
+	if response.Sections == nil {
+		response.Sections = []models.Section{}
+	}
+	for _, w := range dashboard.Widgets {
+		response.Widgets = append(response.Widgets, WidgetLayoutResponse{
+			ID:        w.ID,
+			SectionID: w.SectionID,
+			Position:  w.Position,
+			Layout:    w.Layout,
+		})
+	}
+	return response
+}
+
+// UpdateDashboardLayout applies column counts, section settings and widget
+// placements in one transaction. The resulting layout of the whole
+// dashboard must be free of overlaps; widgets not listed keep their place.
+func UpdateDashboardLayout(c *gin.Context) {
+	id := c.Param("id")
+
+	var request UpdateLayoutRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var dashboard models.Dashboard
+	if err := database.DB.Preload("Sections").Preload("Widgets").First(&dashboard, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dashboard not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if request.Columns != nil {
+			before := audit.DashboardFields(&dashboard)
+			if err := layout.CheckColumns(request.Columns); err != nil {
+				return err
+			}
+			columns := dashboard.Columns.Resolved()
+			for breakpoint, count := range request.Columns {
+				columns[breakpoint] = count
+			}
+			dashboard.Columns = columns
+			if err := tx.Model(&dashboard).UpdateColumn("columns", dashboard.Columns).Error; err != nil {
+				return err
+			}
+			if err := audit.Record(tx, c, models.AuditActionUpdate, audit.EntityDashboard, dashboard.ID, before, audit.DashboardFields(&dashboard)); err != nil {
+				return err
+			}
+		}
+
+		sections := make(map[uint]*models.Section, len(dashboard.Sections))
+		for i := range dashboard.Sections {
+			sections[dashboard.Sections[i].ID] = &dashboard.Sections[i]
+		}
+		for _, update := range request.Sections {
+			section, ok := sections[update.ID]
+			if !ok {
+				return fmt.Errorf("%w: section %d is not on this dashboard", errLayoutRequest, update.ID)
+			}
+			if err := applySectionUpdate(tx, c, section, update.Title, update.Position, update.Collapsed); err != nil {
+				return err
+			}
+		}
+
+		widgets := make(map[uint]*models.Widget, len(dashboard.Widgets))
+		for i := range dashboard.Widgets {
+			widgets[dashboard.Widgets[i].ID] = &dashboard.Widgets[i]
+		}
+		befores := make(map[uint]map[string]interface{})
+		for _, update := range request.Widgets {
+			widget, ok := widgets[update.ID]
+			if !ok {
+				return fmt.Errorf("%w: widget %d is not on this dashboard", errLayoutRequest, update.ID)
+			}
+			if update.SectionID != nil && *update.SectionID != 0 {
+				if _, ok := sections[*update.SectionID]; !ok {
+					return layout.ErrUnknownSection
+				}
+			}
+
+			if err := layout.CheckBreakpoints(update.Layout); err != nil {
+				return err
+			}
+
+			befores[widget.ID] = audit.WidgetFields(widget)
+			sectionID := update.SectionID
+			if sectionID != nil && *sectionID == 0 {
+				sectionID = nil
+			}
+			moved := !layout.SameSection(widget.SectionID, sectionID)
+			widget.SectionID = sectionID
+			if update.Position != nil {
+				widget.Position = *update.Position
+			}
+			// Without a layout the widget keeps its place, unless it moved to
+			// another section and has to be placed again there.
+			if update.Layout != nil {
+				widget.Layout = update.Layout
+			} else if moved {
+				widget.Layout = nil
+			}
+		}
+
+		if conflicts := layout.Validate(dashboard.Columns, dashboard.Widgets); len(conflicts) > 0 {
+			return &layoutConflictError{conflicts: conflicts}
+		}
+
+		var changed []models.Widget
+		for i := range dashboard.Widgets {
+			widget := &dashboard.Widgets[i]
+			before, listed := befores[widget.ID]
+			if !listed && len(widget.Layout) == len(models.Breakpoints) {
+				continue
+			}
+			if !listed {
+				before = audit.WidgetFields(widget)
+			}
+
+			layout.Place(dashboard.Columns, dashboard.Widgets, widget)
+			if err := tx.Model(widget).Updates(map[string]interface{}{
+				"section_id": widget.SectionID,
+				"position":   widget.Position,
+				"layout":     widget.Layout,
+			}).Error; err != nil {
+				return err
+			}
+			if err := audit.Record(tx, c, models.AuditActionUpdate, audit.EntityWidget, widget.ID, before, audit.WidgetFields(widget)); err != nil {
+				return err
+			}
+			changed = append(changed, *widget)
+		}
+
+		return revisions.SaveWidgets(tx, c, changed, dashboard.ID)
+	})
+	if err != nil {
+		if !respondLayoutError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	database.DB.Preload("Sections", orderByPosition).Preload("Widgets", orderByPosition).First(&dashboard, dashboard.ID)
+	c.JSON(http.StatusOK, gin.H{"data": newLayoutResponse(&dashboard)})
+}
+
+func applySectionUpdate(tx *gorm.DB, c *gin.Context, section *models.Section, title *string, position *int, collapsed *bool) error {
+	before := audit.SectionFields(section)
+	if title != nil {
+		section.Title = *title
+	}
+	if position != nil {
+		section.Position = *position
+	}
+	if collapsed != nil {
+		section.Collapsed = *collapsed
+	}
+
+	if err := tx.Save(section).Error; err != nil {
+		return err
+	}
+	return audit.Record(tx, c, models.AuditActionUpdate, audit.EntitySection, section.ID, before, audit.SectionFields(section))
+}
+
+func CreateSection(c *gin.Context) {
+	id := c.Param("id")
+
+	var request SectionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var dashboard models.Dashboard
+	if err := database.DB.First(&dashboard, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dashboard not found"})
+		return
+	}
+
+	section := models.Section{DashboardID: dashboard.ID}
+	if request.Title != nil {
+		section.Title = *request.Title
+	}
+	if request.Collapsed != nil {
+		section.Collapsed = *request.Collapsed
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if request.Position != nil {
+			section.Position = *request.Position
+		} else {
+			var maxPosition *int
+			if err := tx.Model(&models.Section{}).Where("dashboard_id = ?", dashboard.ID).
+				Select("MAX(position)").Scan(&maxPosition).Error; err != nil {
+				return err
+			}
+			if maxPosition != nil {
+				section.Position = *maxPosition + 1
+			}
+		}
+
+		if err := tx.Create(&section).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, c, models.AuditActionCreate, audit.EntitySection, section.ID, nil, audit.SectionFields(&section)); err != nil {
+			return err
+		}
+		return revisions.SaveDashboard(tx, c, dashboard.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": section})
+}
+
+func UpdateSection(c *gin.Context) {
+	section, ok := findSection(c)
+	if !ok {
+		return
+	}
+
+	var request SectionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applySectionUpdate(tx, c, section, request.Title, request.Position, request.Collapsed); err != nil {
+			return err
+		}
+		return revisions.SaveDashboard(tx, c, section.DashboardID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": section})
+}
+
+// DeleteSection removes a section. Its widgets move to the dashboard's
+// top-level grid and are placed below what is already there.
+func DeleteSection(c *gin.Context) {
+	section, ok := findSection(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var widgets []models.Widget
+		if err := tx.Where("section_id = ?", section.ID).Order("position ASC, id ASC").Find(&widgets).Error; err != nil {
+			return err
+		}
+
+		for i := range widgets {
+			before := audit.WidgetFields(&widgets[i])
+			widgets[i].SectionID = nil
+			if err := layout.PlaceInDashboard(tx, &widgets[i]); err != nil {
+				return err
+			}
+			if err := tx.Model(&widgets[i]).Updates(map[string]interface{}{
+				"section_id": nil,
+				"layout":     widgets[i].Layout,
+			}).Error; err != nil {
+				return err
+			}
+			if err := audit.Record(tx, c, models.AuditActionUpdate, audit.EntityWidget, widgets[i].ID, before, audit.WidgetFields(&widgets[i])); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Delete(section).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, c, models.AuditActionDelete, audit.EntitySection, section.ID, audit.SectionFields(section), nil); err != nil {
+			return err
+		}
+		return revisions.SaveWidgets(tx, c, widgets, section.DashboardID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Section deleted successfully"})
+}
+
+func findSection(c *gin.Context) (*models.Section, bool) {
+	sectionID, err := strconv.ParseUint(c.Param("section_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid section ID"})
+		return nil, false
+	}
+
+	var section models.Section
+	if err := database.DB.Where("dashboard_id = ?", c.Param("id")).First(&section, sectionID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Section not found"})
+		return nil, false
+	}
+	return &section, true
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+
+	"dashboard-server/database"
+	"dashboard-server/layout"
+	"dashboard-server/models"
+)
+
+func TestUpdateSectionKeepsOmittedTitle(t *testing.T) {
+	dashboard := setupDB(t)
+	section := models.Section{DashboardID: dashboard.ID, Title: "Media"}
+	if err := database.DB.Create(&section).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	path := fmt.Sprintf("/dashboards/%d/sections/%d", dashboard.ID, section.ID)
+	w := serve(t, http.MethodPut, "/dashboards/:id/sections/:section_id", path, map[string]interface{}{"collapsed": true}, nil, UpdateSection)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+
+	var saved models.Section
+	database.DB.First(&saved, section.ID)
+	if saved.Title != "Media" || !saved.Collapsed {
+		t.Errorf("section = %q collapsed=%v, want \"Media\" collapsed", saved.Title, saved.Collapsed)
+	}
+
+	w = serve(t, http.MethodPut, "/dashboards/:id/sections/:section_id", path, map[string]interface{}{"title": ""}, nil, UpdateSection)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	database.DB.First(&saved, section.ID)
+	if saved.Title != "" {
+		t.Errorf("explicit empty title not applied, got %q", saved.Title)
+	}
+}
+
+func TestUpdateDashboardLayout(t *testing.T) {
+	dashboard := setupDB(t)
+	placed := models.WidgetLayout{
+		models.BreakpointDesktop: {X: 4, Y: 2, W: 4, H: 2},
+		models.BreakpointTablet:  {X: 0, Y: 2, W: 4, H: 2},
+		models.BreakpointMobile:  {X: 0, Y: 4, W: 4, H: 2},
+	}
+	widget := createTestWidget(t, models.Widget{DashboardID: dashboard.ID, Name: "Radarr", Type: "radarr", Config: models.JSON{}, Layout: placed})
+	route, path := "/dashboards/:id/layout", fmt.Sprintf("/dashboards/%d/layout", dashboard.ID)
+
+	t.Run("omitted layout is kept", func(t *testing.T) {
+		body := map[string]interface{}{
+			"widgets": []map[string]interface{}{{"id": widget.ID, "position": 3}},
+		}
+		w := serve(t, http.MethodPut, route, path, body, nil, UpdateDashboardLayout)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", w.Code, w.Body)
+		}
+
+		var saved models.Widget
+		database.DB.First(&saved, widget.ID)
+		if saved.Position != 3 {
+			t.Errorf("position = %d, want 3", saved.Position)
+		}
+		for _, breakpoint := range models.Breakpoints {
+			if saved.Layout[breakpoint] != placed[breakpoint] {
+				t.Errorf("%s = %+v, want %+v", breakpoint, saved.Layout[breakpoint], placed[breakpoint])
+			}
+		}
+	})
+
+	t.Run("out of range values are rejected", func(t *testing.T) {
+		for name, body := range map[string]interface{}{
+			"columns": map[string]interface{}{"columns": map[string]int{"desktop": layout.MaxColumns + 1}},
+			"height": map[string]interface{}{"widgets": []map[string]interface{}{{
+				"id":     widget.ID,
+				"layout": map[string]interface{}{"desktop": map[string]int{"x": 0, "y": 0, "w": 4, "h": 200_000_000}},
+			}}},
+			"overflow": map[string]interface{}{"widgets": []map[string]interface{}{{
+				"id":     widget.ID,
+				"layout": map[string]interface{}{"desktop": map[string]int{"x": math.MaxInt, "y": 0, "w": 1, "h": 1}},
+			}}},
+		} {
+			w := serve(t, http.MethodPut, route, path, body, nil, UpdateDashboardLayout)
+			if w.Code != http.StatusBadRequest && w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("%s: status %d, want 400 or 422: %s", name, w.Code, w.Body)
+			}
+		}
+
+		var saved models.Widget
+		database.DB.First(&saved, widget.ID)
+		if saved.Layout[models.BreakpointDesktop] != placed[models.BreakpointDesktop] {
+			t.Errorf("desktop = %+v, want %+v", saved.Layout[models.BreakpointDesktop], placed[models.BreakpointDesktop])
+		}
+	})
+
+	t.Run("unknown breakpoint is rejected", func(t *testing.T) {
+		body := map[string]interface{}{
+			"widgets": []map[string]interface{}{{
+				"id":     widget.ID,
+				"layout": map[string]interface{}{"widescreen": map[string]int{"x": 0, "y": 0, "w": 4, "h": 2}},
+			}},
+		}
+		var response struct {
+			Error string `json:"error"`
+		}
+		w := serve(t, http.MethodPut, route, path, body, &response, UpdateDashboardLayout)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("status %d, want 400: %s", w.Code, w.Body)
+		}
+
+		var saved models.Widget
+		database.DB.First(&saved, widget.ID)
+		if _, ok := saved.Layout["widescreen"]; ok {
+			t.Error("unknown breakpoint was stored")
+		}
+	})
+}
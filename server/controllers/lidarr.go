@@ -1,16 +1,18 @@
 package controllers
 
 import (
-	"crypto/tls"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	"dashboard-server/database"
+	"dashboard-server/metrics"
 	"dashboard-server/models"
+	"dashboard-server/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -45,13 +47,6 @@ type LidarrQueueItem struct {
 	SizeLeft float64 `json:"sizeleft"`
 }
 
-type LidarrDiskSpace struct {
-	Path       string `json:"path"`
-	Label      string `json:"label"`
-	FreeSpace  int64  `json:"freeSpace"`
-	TotalSpace int64  `json:"totalSpace"`
-}
-
 type LidarrHealthCheck struct {
 	Source  string `json:"source"`
 	Type    string `json:"type"`
@@ -80,46 +75,27 @@ func TestLidarrConnection(c *gin.Context) {
 		return
 	}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-	}
-
-	req, err := http.NewRequest("GET", config.ServerURL+"/api/v1/system/status", nil)
+	client, err := services.NewArrClient("lidarr", config.ServerURL, config.ApiKey)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server URL"})
-		return
-	}
-
-	req.Header.Set("X-Api-Key", config.ApiKey)
-	req.Header.Set("Accept", "application/json")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "Cannot connect to Lidarr server"})
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == 401 {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
-		return
-	}
-
-	if resp.StatusCode != 200 {
-		c.JSON(resp.StatusCode, gin.H{"error": fmt.Sprintf("Lidarr returned status %d", resp.StatusCode)})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	var systemStatus LidarrSystemStatus
-	if err := json.NewDecoder(resp.Body).Decode(&systemStatus); err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "Invalid response from Lidarr"})
+	if err := client.Get(c.Request.Context(), "system/status", nil, &systemStatus); err != nil {
+		var statusErr *services.ArrStatusError
+		switch {
+		case errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+		case errors.As(err, &statusErr):
+			c.JSON(statusErr.StatusCode, gin.H{"error": fmt.Sprintf("Lidarr returned status %d", statusErr.StatusCode)})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Cannot connect to Lidarr server"})
+		}
 		return
 	}
 
-	stats, err := fetchLidarrStats(client, config.ServerURL, config.ApiKey)
+	stats, err := fetchLidarrStats(c.Request.Context(), client)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch Lidarr statistics: " + err.Error()})
 		return
@@ -160,145 +136,82 @@ func ProxyLidarrStats(c *gin.Context) {
 		return
 	}
 
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
+	client, err := services.NewArrClient("lidarr", serverURL, apiKey)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	stats, err := fetchLidarrStats(client, serverURL, apiKey)
+	start := time.Now()
+	stats, err := fetchLidarrStats(c.Request.Context(), client)
+	metrics.ObserveFetch("lidarr", start, err)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
 	}
 
+	metrics.SetWidgetValues(widget.ID, widget.Name, widget.Type, map[string]float64{
+		"queued_items":        float64(stats.QueuedItems),
+		"download_progress":   stats.DownloadProgress,
+		"monitored_artists":   float64(stats.MonitoredArtists),
+		"total_albums":        float64(stats.TotalAlbums),
+		"total_tracks":        float64(stats.TotalTracks),
+		"tracks_with_files":   float64(stats.TracksWithFiles),
+		"total_storage_bytes": float64(stats.TotalStorage),
+		"free_storage_bytes":  float64(stats.FreeStorage),
+		"health_alerts":       float64(len(stats.HealthAlerts)),
+	})
+
 	c.JSON(http.StatusOK, stats)
 }
 
-func fetchLidarrStats(client *http.Client, serverURL, apiKey string) (*LidarrStats, error) {
+func fetchLidarrStats(ctx context.Context, client *services.ArrClient) (*LidarrStats, error) {
 	stats := &LidarrStats{}
 
-	queueReq, err := http.NewRequest("GET", serverURL+"/api/v1/queue?pageSize=100", nil)
-	if err != nil {
-		return stats, fmt.Errorf("failed to create queue request: %v", err)
-	}
-	queueReq.Header.Set("X-Api-Key", apiKey)
-	queueReq.Header.Set("Accept", "application/json")
+	var queue LidarrQueue
+	if err := client.Get(ctx, "queue", url.Values{"pageSize": {"100"}}, &queue); err == nil {
+		stats.QueuedItems = queue.TotalRecords
 
-	queueResp, err := client.Do(queueReq)
-	if err != nil {
-		return stats, fmt.Errorf("failed to fetch queue: %v", err)
-	}
-	defer queueResp.Body.Close()
-
-	if queueResp.StatusCode == 200 {
-		var queue LidarrQueue
-		queueBody, _ := io.ReadAll(queueResp.Body)
-		if err := json.Unmarshal(queueBody, &queue); err == nil {
-			stats.QueuedItems = queue.TotalRecords
-
-			var totalSize, completedSize float64
-			for _, item := range queue.Records {
-				totalSize += item.Size
-				completedSize += (item.Size - item.SizeLeft)
-			}
-			if totalSize > 0 {
-				stats.DownloadProgress = (completedSize / totalSize) * 100
-			}
+		var totalSize, completedSize float64
+		for _, item := range queue.Records {
+			totalSize += item.Size
+			completedSize += (item.Size - item.SizeLeft)
 		}
+		if totalSize > 0 {
+			stats.DownloadProgress = (completedSize / totalSize) * 100
+		}
+	} else if !services.IsArrStatusError(err) {
+		return stats, fmt.Errorf("failed to fetch queue: %v", err)
 	}
 
-	artistReq, err := http.NewRequest("GET", serverURL+"/api/v1/artist", nil)
-	if err != nil {
-		return stats, fmt.Errorf("failed to create artist request: %v", err)
-	}
-	artistReq.Header.Set("X-Api-Key", apiKey)
-	artistReq.Header.Set("Accept", "application/json")
-
-	artistResp, err := client.Do(artistReq)
-	if err != nil {
-		return stats, fmt.Errorf("failed to fetch artists: %v", err)
-	}
-	defer artistResp.Body.Close()
-
-	if artistResp.StatusCode == 200 {
-		var artists []LidarrArtist
-		artistBody, _ := io.ReadAll(artistResp.Body)
-		if err := json.Unmarshal(artistBody, &artists); err == nil {
-			for _, artist := range artists {
-				if artist.Monitored {
-					stats.MonitoredArtists++
-				}
-				stats.TotalAlbums += artist.Statistics.AlbumCount
-				stats.TotalTracks += artist.Statistics.TotalTrackCount
-				stats.TracksWithFiles += artist.Statistics.TrackFileCount
+	var artists []LidarrArtist
+	if err := client.Get(ctx, "artist", nil, &artists); err == nil {
+		for _, artist := range artists {
+			if artist.Monitored {
+				stats.MonitoredArtists++
 			}
+			stats.TotalAlbums += artist.Statistics.AlbumCount
+			stats.TotalTracks += artist.Statistics.TotalTrackCount
+			stats.TracksWithFiles += artist.Statistics.TrackFileCount
 		}
+	} else if !services.IsArrStatusError(err) {
+		return stats, fmt.Errorf("failed to fetch artists: %v", err)
 	}
 
 	stats.MissingAlbums = 0
 
-	diskReq, err := http.NewRequest("GET", serverURL+"/api/v1/diskspace", nil)
-	if err != nil {
-		return stats, fmt.Errorf("failed to create diskspace request: %v", err)
-	}
-	diskReq.Header.Set("X-Api-Key", apiKey)
-	diskReq.Header.Set("Accept", "application/json")
-
-	diskResp, err := client.Do(diskReq)
-	if err != nil {
+	if total, free, err := client.LibraryStorage(ctx); err == nil {
+		stats.TotalStorage = total
+		stats.FreeStorage = free
+	} else if !services.IsArrStatusError(err) {
 		return stats, fmt.Errorf("failed to fetch disk space: %v", err)
 	}
-	defer diskResp.Body.Close()
-
-	if diskResp.StatusCode == 200 {
-		var diskSpaces []LidarrDiskSpace
-		diskBody, _ := io.ReadAll(diskResp.Body)
-		if err := json.Unmarshal(diskBody, &diskSpaces); err == nil && len(diskSpaces) > 0 {
-			stats.FreeStorage = diskSpaces[3].FreeSpace
-			stats.TotalStorage = diskSpaces[3].TotalSpace
-		}
-	}
 
-	if healthChecks, err := fetchLidarrHealth(client, serverURL, apiKey); err == nil {
+	stats.HealthAlerts = []LidarrHealthCheck{}
+	var healthChecks []LidarrHealthCheck
+	if err := client.Get(ctx, "health", nil, &healthChecks); err == nil {
 		stats.HealthAlerts = healthChecks
-	} else {
-		stats.HealthAlerts = []LidarrHealthCheck{}
 	}
 
 	return stats, nil
 }
-
-func fetchLidarrHealth(client *http.Client, serverURL, apiKey string) ([]LidarrHealthCheck, error) {
-	url := fmt.Sprintf("%s/api/v1/health?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var healthChecks []LidarrHealthCheck
-	if err := json.Unmarshal(body, &healthChecks); err != nil {
-		return nil, err
-	}
-
-	return healthChecks, nil
-}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"dashboard-server/database"
+	"dashboard-server/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupDB points database.DB at a fresh SQLite file holding only the
+// default dashboard, which it returns.
+func setupDB(t *testing.T) models.Dashboard {
+	t.Helper()
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "test.db"))
+	database.InitDatabase()
+	t.Cleanup(func() {
+		if sqlDB, err := database.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	var dashboard models.Dashboard
+	if err := database.DB.First(&dashboard).Error; err != nil {
+		t.Fatal(err)
+	}
+	return dashboard
+}
+
+func createTestWidget(t *testing.T, widget models.Widget) models.Widget {
+	t.Helper()
+	if err := database.DB.Create(&widget).Error; err != nil {
+		t.Fatal(err)
+	}
+	return widget
+}
+
+// serve sends one request through a router with handlers registered at
+// route and decodes the JSON response into out when it is not nil.
+func serve(t *testing.T, method, route, path string, body, out interface{}, handlers ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Handle(method, route, handlers...)
+
+	var raw []byte
+	if body != nil {
+		var err error
+		if raw, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: invalid JSON response %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"dashboard-server/database"
+	"dashboard-server/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type MeResponse struct {
+	User            string                `json:"user"`
+	Authenticated   bool                  `json:"authenticated"`
+	HomeDashboardID *uint                 `json:"home_dashboard_id"`
+	Preferences     models.UserPreference `json:"preferences"`
+}
+
+func preferenceUser(c *gin.Context) string {
+	if user := c.GetString("user"); user != "" {
+		return user
+	}
+	return models.AnonymousUser
+}
+
+func loadPreferences(user string) (models.UserPreference, error) {
+	var preferences models.UserPreference
+	err := database.DB.First(&preferences, "user = ?", user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultPreferences(user), nil
+	}
+	return preferences, err
+}
+
+// homeDashboardID picks the dashboard a request is about: an explicit
+// dashboard_id query parameter, then the caller's default dashboard, then the
+// first dashboard. It returns 0 when there are no dashboards.
+func homeDashboardID(c *gin.Context) uint {
+	if id, err := strconv.ParseUint(c.Query("dashboard_id"), 10, 32); err == nil && id > 0 {
+		return uint(id)
+	}
+
+	var dashboard models.Dashboard
+	if preferences, err := loadPreferences(preferenceUser(c)); err == nil && preferences.DefaultDashboardID != nil {
+		if database.DB.First(&dashboard, *preferences.DefaultDashboardID).Error == nil {
+			return dashboard.ID
+		}
+	}
+	if database.DB.Order("id").First(&dashboard).Error == nil {
+		return dashboard.ID
+	}
+	return 0
+}
+
+func GetMe(c *gin.Context) {
+	user := preferenceUser(c)
+
+	preferences, err := loadPreferences(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := MeResponse{
+		User:          user,
+		Authenticated: c.GetString("user") != "",
+		Preferences:   preferences,
+	}
+	if id := homeDashboardID(c); id != 0 {
+		response.HomeDashboardID = &id
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func UpdatePreferences(c *gin.Context) {
+	user := preferenceUser(c)
+
+	preferences, err := loadPreferences(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&preferences); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	preferences.User = user
+
+	if err := preferences.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if preferences.DefaultDashboardID != nil {
+		var dashboard models.Dashboard
+		if err := database.DB.First(&dashboard, *preferences.DefaultDashboardID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Dashboard not found"})
+			return
+		}
+	}
+
+	if err := database.DB.Save(&preferences).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": preferences})
+}
@@ -1,16 +1,17 @@
 package controllers
 
 import (
-	"crypto/tls"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	"dashboard-server/database"
+	"dashboard-server/metrics"
 	"dashboard-server/models"
+	"dashboard-server/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -57,7 +58,7 @@ type ProwlarrStats struct {
 func TestProwlarrConnection(c *gin.Context) {
 	var config ProwlarrTestConfig
 	if err := c.ShouldBindJSON(&config); err != nil {
-		fmt.Println("Error binding JSON:", err)
+		slog.DebugContext(c.Request.Context(), "Invalid test configuration", "integration", "prowlarr", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration: " + err.Error()})
 		return
 	}
@@ -72,7 +73,7 @@ func TestProwlarrConnection(c *gin.Context) {
 		return
 	}
 
-	stats, err := fetchProwlarrStats(config.ServerURL, config.ApiKey)
+	stats, err := fetchProwlarrStats(c.Request.Context(), config.ServerURL, config.ApiKey)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
@@ -113,32 +114,34 @@ func ProxyProwlarrStats(c *gin.Context) {
 		return
 	}
 
-	stats, err := fetchProwlarrStats(serverURL, apiKey)
+	start := time.Now()
+	stats, err := fetchProwlarrStats(c.Request.Context(), serverURL, apiKey)
+	metrics.ObserveFetch("prowlarr", start, err)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
 	}
 
+	metrics.SetWidgetValues(widget.ID, widget.Name, widget.Type, map[string]float64{
+		"total_queries":        float64(stats.TotalQueries),
+		"total_grabs":          float64(stats.TotalGrabs),
+		"total_failed_queries": float64(stats.TotalFailedQueries),
+		"active_indexers":      float64(stats.ActiveIndexers),
+		"alerts":               float64(len(stats.Alerts)),
+	})
+
 	c.JSON(http.StatusOK, stats)
 }
 
-func fetchProwlarrStats(serverURL, apiKey string) (*ProwlarrStats, error) {
-	if serverURL[len(serverURL)-1] == '/' {
-		serverURL = serverURL[:len(serverURL)-1]
-	}
-
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		},
+func fetchProwlarrStats(ctx context.Context, serverURL, apiKey string) (*ProwlarrStats, error) {
+	client, err := services.NewArrClient("prowlarr", serverURL, apiKey)
+	if err != nil {
+		return nil, err
 	}
 
 	stats := &ProwlarrStats{}
-	indexerStats, err := fetchProwlarrIndexerStats(client, serverURL, apiKey)
-	if err != nil {
+	var indexerStats ProwlarrIndexerStatsResponse
+	if err := client.Get(ctx, "indexerstats", nil, &indexerStats); err != nil {
 		return nil, fmt.Errorf("failed to fetch indexer stats: %v", err)
 	}
 
@@ -161,7 +164,8 @@ func fetchProwlarrStats(serverURL, apiKey string) (*ProwlarrStats, error) {
 
 	var alerts = make([]Alert, 0)
 
-	if healthChecks, err := fetchProwlarrHealth(client, serverURL, apiKey); err == nil {
+	var healthChecks []ProwlarrHealthCheck
+	if err := client.Get(ctx, "health", nil, &healthChecks); err == nil {
 		for _, health := range healthChecks {
 			alerts = append(alerts, Alert{
 				Message: health.Message,
@@ -191,69 +195,3 @@ func fetchProwlarrStats(serverURL, apiKey string) (*ProwlarrStats, error) {
 
 	return stats, nil
 }
-
-func fetchProwlarrIndexerStats(client *http.Client, serverURL, apiKey string) (*ProwlarrIndexerStatsResponse, error) {
-	url := fmt.Sprintf("%s/api/v1/indexerstats?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var stats ProwlarrIndexerStatsResponse
-	if err := json.Unmarshal(body, &stats); err != nil {
-		return nil, err
-	}
-
-	return &stats, nil
-}
-
-func fetchProwlarrHealth(client *http.Client, serverURL, apiKey string) ([]ProwlarrHealthCheck, error) {
-	url := fmt.Sprintf("%s/api/v1/health?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var healthChecks []ProwlarrHealthCheck
-	if err := json.Unmarshal(body, &healthChecks); err != nil {
-		return nil, err
-	}
-
-	return healthChecks, nil
-}
@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"dashboard-server/database"
+	"dashboard-server/metrics"
+	"dashboard-server/models"
+	"dashboard-server/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProxmoxTestConfig struct {
+	ServerURL     string  `json:"serverUrl" binding:"required"`
+	TokenID       string  `json:"tokenId" binding:"required"`
+	TokenSecret   string  `json:"tokenSecret" binding:"required"`
+	TLSSkipVerify bool    `json:"tlsSkipVerify"`
+	BackupWindow  float64 `json:"backupWindow"`
+}
+
+type ProxmoxNode struct {
+	Name        string  `json:"name"`
+	Online      bool    `json:"online"`
+	CPU         float64 `json:"cpu"`
+	Cores       int     `json:"cores"`
+	MemoryUsed  int64   `json:"memoryUsed"`
+	MemoryTotal int64   `json:"memoryTotal"`
+	DiskUsed    int64   `json:"diskUsed"`
+	DiskTotal   int64   `json:"diskTotal"`
+	Uptime      int64   `json:"uptime"`
+}
+
+type ProxmoxGuests struct {
+	Running int `json:"running"`
+	Stopped int `json:"stopped"`
+	Total   int `json:"total"`
+}
+
+type ProxmoxStorage struct {
+	Name    string  `json:"name"`
+	Node    string  `json:"node"`
+	Type    string  `json:"type"`
+	Shared  bool    `json:"shared"`
+	Used    int64   `json:"used"`
+	Total   int64   `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+type ProxmoxBackupFailure struct {
+	Node      string    `json:"node"`
+	Guest     string    `json:"guest"`
+	Status    string    `json:"status"`
+	StartedAt time.Time `json:"startedAt"`
+	EndedAt   time.Time `json:"endedAt"`
+}
+
+type ProxmoxStats struct {
+	Nodes         []ProxmoxNode          `json:"nodes"`
+	VMs           ProxmoxGuests          `json:"vms"`
+	Containers    ProxmoxGuests          `json:"containers"`
+	Storage       []ProxmoxStorage       `json:"storage"`
+	FailedBackups []ProxmoxBackupFailure `json:"failedBackups"`
+	Alerts        []Alert                `json:"alerts"`
+}
+
+const defaultProxmoxBackupWindow = 24 * time.Hour
+
+func TestProxmoxConnection(c *gin.Context) {
+	var config ProxmoxTestConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		slog.DebugContext(c.Request.Context(), "Invalid test configuration", "integration", "proxmox", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration: " + err.Error()})
+		return
+	}
+
+	stats, err := fetchProxmoxStats(c.Request.Context(), config)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func ProxyProxmoxStats(c *gin.Context) {
+	widgetIDStr := c.Param("widget_id")
+	widgetID, err := strconv.ParseUint(widgetIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid widget ID"})
+		return
+	}
+
+	widget := &models.Widget{}
+	if err := database.DB.First(widget, uint(widgetID)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Widget not found"})
+		return
+	}
+
+	if widget.Type != "proxmox" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Widget is not a Proxmox widget"})
+		return
+	}
+
+	config := ProxmoxTestConfig{}
+	var ok bool
+	if config.ServerURL, ok = widget.Config["serverUrl"].(string); !ok || config.ServerURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "serverUrl not found in widget configuration"})
+		return
+	}
+	if config.TokenID, ok = widget.Config["tokenId"].(string); !ok || config.TokenID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tokenId not found in widget configuration"})
+		return
+	}
+	if config.TokenSecret, ok = widget.Config["tokenSecret"].(string); !ok || config.TokenSecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tokenSecret not found in widget configuration"})
+		return
+	}
+	config.TLSSkipVerify, _ = widget.Config["tlsSkipVerify"].(bool)
+	config.BackupWindow, _ = widget.Config["backupWindow"].(float64)
+
+	start := time.Now()
+	stats, err := fetchProxmoxStats(c.Request.Context(), config)
+	metrics.ObserveFetch("proxmox", start, err)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	onlineNodes := 0
+	for _, node := range stats.Nodes {
+		if node.Online {
+			onlineNodes++
+		}
+	}
+	metrics.SetWidgetValues(widget.ID, widget.Name, widget.Type, map[string]float64{
+		"online_nodes":       float64(onlineNodes),
+		"total_nodes":        float64(len(stats.Nodes)),
+		"running_vms":        float64(stats.VMs.Running),
+		"stopped_vms":        float64(stats.VMs.Stopped),
+		"running_containers": float64(stats.Containers.Running),
+		"stopped_containers": float64(stats.Containers.Stopped),
+		"failed_backups":     float64(len(stats.FailedBackups)),
+	})
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func fetchProxmoxStats(ctx context.Context, config ProxmoxTestConfig) (*ProxmoxStats, error) {
+	client, err := services.NewProxmoxClient(config.ServerURL, config.TokenID, config.TokenSecret, config.TLSSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []services.ProxmoxResource
+	if err := client.Get(ctx, "cluster/resources", nil, &resources); err != nil {
+		return nil, fmt.Errorf("failed to fetch cluster resources: %v", err)
+	}
+
+	stats := &ProxmoxStats{
+		Nodes:         []ProxmoxNode{},
+		Storage:       []ProxmoxStorage{},
+		FailedBackups: []ProxmoxBackupFailure{},
+		Alerts:        []Alert{},
+	}
+
+	guestNames := make(map[string]string)
+	sharedStorage := make(map[string]bool)
+	for _, resource := range resources {
+		switch resource.Type {
+		case "node":
+			node := ProxmoxNode{
+				Name:        resource.Node,
+				Online:      resource.Status == "online",
+				CPU:         resource.CPU * 100,
+				Cores:       int(resource.MaxCPU),
+				MemoryUsed:  resource.Mem,
+				MemoryTotal: resource.MaxMem,
+				DiskUsed:    resource.Disk,
+				DiskTotal:   resource.MaxDisk,
+				Uptime:      resource.Uptime,
+			}
+			stats.Nodes = append(stats.Nodes, node)
+			if !node.Online {
+				stats.Alerts = append(stats.Alerts, Alert{
+					Message: fmt.Sprintf("Node %s is offline", node.Name),
+					Level:   "error",
+				})
+			}
+
+		case "qemu", "lxc":
+			guestNames[strconv.Itoa(resource.VMID)] = resource.Name
+			if resource.Template == 1 {
+				continue
+			}
+			guests := &stats.VMs
+			if resource.Type == "lxc" {
+				guests = &stats.Containers
+			}
+			guests.Total++
+			if resource.Status == "running" {
+				guests.Running++
+			} else {
+				guests.Stopped++
+			}
+
+		case "storage":
+			if resource.Status != "available" || resource.MaxDisk == 0 {
+				continue
+			}
+			// Shared storage is listed once per node. Only available
+			// entries count, so a node that cannot reach it does not
+			// hide it.
+			if resource.Shared == 1 {
+				if sharedStorage[resource.Storage] {
+					continue
+				}
+				sharedStorage[resource.Storage] = true
+			}
+			storage := ProxmoxStorage{
+				Name:   resource.Storage,
+				Node:   resource.Node,
+				Type:   resource.PluginType,
+				Shared: resource.Shared == 1,
+				Used:   resource.Disk,
+				Total:  resource.MaxDisk,
+			}
+			storage.Percent = float64(storage.Used) / float64(storage.Total) * 100
+			stats.Storage = append(stats.Storage, storage)
+		}
+	}
+
+	sort.Slice(stats.Nodes, func(i, j int) bool {
+		return stats.Nodes[i].Name < stats.Nodes[j].Name
+	})
+	sort.Slice(stats.Storage, func(i, j int) bool {
+		if stats.Storage[i].Name != stats.Storage[j].Name {
+			return stats.Storage[i].Name < stats.Storage[j].Name
+		}
+		return stats.Storage[i].Node < stats.Storage[j].Node
+	})
+
+	window := defaultProxmoxBackupWindow
+	if config.BackupWindow > 0 {
+		window = time.Duration(config.BackupWindow * float64(time.Hour))
+	}
+	since := time.Now().Add(-window)
+
+	var tasks []services.ProxmoxTask
+	if err := client.Get(ctx, "cluster/tasks", nil, &tasks); err != nil {
+		// Typically a token without Sys.Audit. The rest of the stats are
+		// still good, but a missing backup failure must not look like none.
+		slog.WarnContext(ctx, "Failed to fetch Proxmox tasks", "error", err)
+		stats.Alerts = append(stats.Alerts, Alert{
+			Message: fmt.Sprintf("Could not read backup status: %v", err),
+			Level:   "warning",
+		})
+	}
+	for _, task := range tasks {
+		if task.Type != "vzdump" || task.EndTime == 0 || task.Status == "" || task.Status == "OK" {
+			continue
+		}
+		ended := time.Unix(task.EndTime, 0)
+		if ended.Before(since) {
+			continue
+		}
+
+		guest := task.ID
+		if name := guestNames[task.ID]; name != "" {
+			guest = fmt.Sprintf("%s (%s)", name, task.ID)
+		}
+		if guest == "" {
+			guest = "all guests"
+		}
+		// vzdump reports "WARNINGS: n" when the backup was written but
+		// something, such as an unreadable file, needs a look.
+		if count, ok := strings.CutPrefix(task.Status, "WARNINGS:"); ok {
+			stats.Alerts = append(stats.Alerts, Alert{
+				Message: fmt.Sprintf("Backup of %s on %s finished with warnings (%s)", guest, task.Node, strings.TrimSpace(count)),
+				Level:   "warning",
+			})
+			continue
+		}
+		failure := ProxmoxBackupFailure{
+			Node:      task.Node,
+			Guest:     guest,
+			Status:    task.Status,
+			StartedAt: time.Unix(task.StartTime, 0),
+			EndedAt:   ended,
+		}
+		stats.FailedBackups = append(stats.FailedBackups, failure)
+		stats.Alerts = append(stats.Alerts, Alert{
+			Message: fmt.Sprintf("Backup of %s on %s failed: %s", failure.Guest, failure.Node, strings.TrimSpace(failure.Status)),
+			Level:   "error",
+		})
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchProxmoxStats(t *testing.T) {
+	ended := time.Now().Add(-time.Hour).Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "PVEAPIToken=dash@pve!widget=s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var data interface{}
+		switch r.URL.Path {
+		case "/api2/json/cluster/resources":
+			data = []map[string]interface{}{
+				{"type": "node", "node": "pve1", "status": "online", "cpu": 0.25, "maxcpu": 8},
+				{"type": "node", "node": "pve2", "status": "offline"},
+				{"type": "qemu", "vmid": 100, "name": "nas", "node": "pve1", "status": "running"},
+				{"type": "lxc", "vmid": 101, "name": "pihole", "node": "pve1", "status": "stopped"},
+				{"type": "qemu", "vmid": 9000, "name": "template", "node": "pve1", "template": 1},
+				{"type": "storage", "storage": "local", "node": "pve1", "status": "available", "disk": 25, "maxdisk": 100},
+				// The offline node lists the NFS share first, unavailable.
+				{"type": "storage", "storage": "nfs", "node": "pve2", "shared": 1, "status": "unknown"},
+				{"type": "storage", "storage": "nfs", "node": "pve1", "shared": 1, "status": "available", "disk": 50, "maxdisk": 200},
+				{"type": "storage", "storage": "nfs", "node": "pve3", "shared": 1, "status": "available", "disk": 50, "maxdisk": 200},
+			}
+		case "/api2/json/cluster/tasks":
+			data = []map[string]interface{}{
+				{"type": "vzdump", "id": "100", "node": "pve1", "status": "OK", "starttime": ended - 60, "endtime": ended},
+				{"type": "vzdump", "id": "101", "node": "pve1", "status": "WARNINGS: 2", "starttime": ended - 60, "endtime": ended},
+				{"type": "vzdump", "id": "102", "node": "pve1", "status": "job errors", "starttime": ended - 60, "endtime": ended},
+				{"type": "vzdump", "id": "100", "node": "pve1", "status": "job errors", "starttime": ended - 3*86400, "endtime": ended - 3*86400},
+				{"type": "vzdump", "id": "100", "node": "pve1", "starttime": ended},
+				{"type": "qmstart", "id": "100", "node": "pve1", "status": "start failed", "starttime": ended - 60, "endtime": ended},
+			}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer server.Close()
+
+	stats, err := fetchProxmoxStats(context.Background(), ProxmoxTestConfig{
+		ServerURL:   server.URL,
+		TokenID:     "dash@pve!widget",
+		TokenSecret: "s3cret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stats.Nodes) != 2 || stats.Nodes[0].CPU != 25 || stats.Nodes[1].Online {
+		t.Errorf("nodes = %+v", stats.Nodes)
+	}
+	if stats.VMs != (ProxmoxGuests{Running: 1, Total: 1}) || stats.Containers != (ProxmoxGuests{Stopped: 1, Total: 1}) {
+		t.Errorf("vms = %+v, containers = %+v", stats.VMs, stats.Containers)
+	}
+
+	if len(stats.Storage) != 2 {
+		t.Fatalf("storage = %+v", stats.Storage)
+	}
+	if nfs := stats.Storage[1]; nfs.Name != "nfs" || nfs.Node != "pve1" || !nfs.Shared || nfs.Percent != 25 {
+		t.Errorf("shared storage = %+v", nfs)
+	}
+
+	if len(stats.FailedBackups) != 1 || stats.FailedBackups[0].Guest != "102" || stats.FailedBackups[0].Status != "job errors" {
+		t.Errorf("failed backups = %+v", stats.FailedBackups)
+	}
+
+	alerts := map[string]string{}
+	for _, alert := range stats.Alerts {
+		alerts[alert.Message] = alert.Level
+	}
+	want := map[string]string{
+		"Node pve2 is offline": "error",
+		"Backup of pihole (101) on pve1 finished with warnings (2)": "warning",
+		"Backup of 102 on pve1 failed: job errors":                  "error",
+	}
+	if len(alerts) != len(want) {
+		t.Errorf("alerts = %v, want %v", alerts, want)
+	}
+	for message, level := range want {
+		if alerts[message] != level {
+			t.Errorf("alert %q level = %q, want %q", message, alerts[message], level)
+		}
+	}
+}
+
+func TestFetchProxmoxStatsWithoutTaskAccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api2/json/cluster/resources":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]interface{}{
+				{"type": "node", "node": "pve1", "status": "online", "maxcpu": 8},
+			}})
+		case "/api2/json/cluster/tasks":
+			http.Error(w, "Permission check failed (/, Sys.Audit)", http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	stats, err := fetchProxmoxStats(context.Background(), ProxmoxTestConfig{
+		ServerURL:   server.URL,
+		TokenID:     "dash@pve!widget",
+		TokenSecret: "s3cret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Nodes) != 1 {
+		t.Errorf("nodes = %+v", stats.Nodes)
+	}
+	if len(stats.Alerts) != 1 || stats.Alerts[0].Level != "warning" || !strings.HasPrefix(stats.Alerts[0].Message, "Could not read backup status: ") {
+		t.Errorf("alerts = %+v, want one backup status warning", stats.Alerts)
+	}
+}
@@ -1,7 +1,7 @@
 package controllers
 
 import (
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,7 +12,9 @@ import (
 	"time"
 
 	"dashboard-server/database"
+	"dashboard-server/metrics"
 	"dashboard-server/models"
+	"dashboard-server/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -93,7 +95,9 @@ func ProxyQBittorrentStats(c *gin.Context) {
 		qbitConfig.MaxUploadSpeed = int(maxUpload)
 	}
 
-	stats, err := fetchQBittorrentStats(qbitConfig)
+	start := time.Now()
+	stats, err := fetchQBittorrentStats(c.Request.Context(), qbitConfig)
+	metrics.ObserveFetch("qbittorrent", start, err)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -102,6 +106,15 @@ func ProxyQBittorrentStats(c *gin.Context) {
 		return
 	}
 
+	metrics.SetWidgetValues(widget.ID, widget.Name, widget.Type, map[string]float64{
+		"downloading_torrents": float64(stats.DownloadingTorrents),
+		"seeding_torrents":     float64(stats.SeedingTorrents),
+		"error_torrents":       float64(stats.ErrorTorrents),
+		"total_torrents":       float64(stats.TotalTorrents),
+		"download_speed_bytes": stats.DownloadSpeed,
+		"upload_speed_bytes":   stats.UploadSpeed,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    stats,
@@ -115,7 +128,7 @@ func TestQBittorrentConnection(c *gin.Context) {
 		return
 	}
 
-	_, err := fetchQBittorrentStats(config)
+	_, err := fetchQBittorrentStats(c.Request.Context(), config)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
@@ -130,29 +143,26 @@ func TestQBittorrentConnection(c *gin.Context) {
 	})
 }
 
-func fetchQBittorrentStats(config QBittorrentConfig) (*QBittorrentStats, error) {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
-	client := &http.Client{Transport: tr, Timeout: 30 * time.Second}
+func fetchQBittorrentStats(ctx context.Context, config QBittorrentConfig) (*QBittorrentStats, error) {
+	client := services.NewHTTPClient(30*time.Second, true)
 
 	baseURL := strings.TrimSuffix(config.ServerURL, "/")
 
 	var cookie string
 	if config.Username != "" && config.Password != "" {
-		loginCookie, err := qbittorrentLogin(client, baseURL, config.Username, config.Password)
+		loginCookie, err := qbittorrentLogin(ctx, client, baseURL, config.Username, config.Password)
 		if err != nil {
 			return nil, fmt.Errorf("failed to login: %v", err)
 		}
 		cookie = loginCookie
 	}
 
-	torrents, err := getQBittorrentTorrents(client, baseURL, cookie)
+	torrents, err := getQBittorrentTorrents(ctx, client, baseURL, cookie)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get torrents: %v", err)
 	}
 
-	globalStats, err := getQBittorrentGlobalStats(client, baseURL, cookie)
+	globalStats, err := getQBittorrentGlobalStats(ctx, client, baseURL, cookie)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get global stats: %v", err)
 	}
@@ -178,14 +188,14 @@ func fetchQBittorrentStats(config QBittorrentConfig) (*QBittorrentStats, error)
 	return stats, nil
 }
 
-func qbittorrentLogin(client *http.Client, baseURL, username, password string) (string, error) {
+func qbittorrentLogin(ctx context.Context, client *http.Client, baseURL, username, password string) (string, error) {
 	loginURL := baseURL + "/api/v2/auth/login"
 
 	data := url.Values{}
 	data.Set("username", username)
 	data.Set("password", password)
 
-	req, err := http.NewRequest("POST", loginURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", loginURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return "", err
 	}
@@ -219,10 +229,10 @@ func qbittorrentLogin(client *http.Client, baseURL, username, password string) (
 	return "", fmt.Errorf("no session cookie found")
 }
 
-func getQBittorrentTorrents(client *http.Client, baseURL, cookie string) ([]QBittorrentTorrent, error) {
+func getQBittorrentTorrents(ctx context.Context, client *http.Client, baseURL, cookie string) ([]QBittorrentTorrent, error) {
 	torrentURL := baseURL + "/api/v2/torrents/info"
 
-	req, err := http.NewRequest("GET", torrentURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", torrentURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -250,10 +260,10 @@ func getQBittorrentTorrents(client *http.Client, baseURL, cookie string) ([]QBit
 	return torrents, nil
 }
 
-func getQBittorrentGlobalStats(client *http.Client, baseURL, cookie string) (*QBittorrentGlobalStats, error) {
+func getQBittorrentGlobalStats(ctx context.Context, client *http.Client, baseURL, cookie string) (*QBittorrentGlobalStats, error) {
 	statsURL := baseURL + "/api/v2/transfer/info"
 
-	req, err := http.NewRequest("GET", statsURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", statsURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,16 +1,17 @@
 package controllers
 
 import (
-	"crypto/tls"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	"dashboard-server/database"
+	"dashboard-server/metrics"
 	"dashboard-server/models"
+	"dashboard-server/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -40,13 +41,6 @@ type RadarrQueueRecord struct {
 	Sizeleft int64 `json:"sizeleft"`
 }
 
-type RadarrDiskSpace struct {
-	Path       string `json:"path"`
-	Label      string `json:"label"`
-	FreeSpace  int64  `json:"freeSpace"`
-	TotalSpace int64  `json:"totalSpace"`
-}
-
 type RadarrHealthCheck struct {
 	Source  string `json:"source"`
 	Type    string `json:"type"`
@@ -69,7 +63,7 @@ type RadarrStats struct {
 func TestRadarrConnection(c *gin.Context) {
 	var config RadarrTestConfig
 	if err := c.ShouldBindJSON(&config); err != nil {
-		fmt.Println("Error binding JSON:", err)
+		slog.DebugContext(c.Request.Context(), "Invalid test configuration", "integration", "radarr", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration: " + err.Error()})
 		return
 	}
@@ -84,7 +78,7 @@ func TestRadarrConnection(c *gin.Context) {
 		return
 	}
 
-	stats, err := fetchRadarrStats(config.ServerURL, config.ApiKey)
+	stats, err := fetchRadarrStats(c.Request.Context(), config.ServerURL, config.ApiKey)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
@@ -125,37 +119,43 @@ func ProxyRadarrStats(c *gin.Context) {
 		return
 	}
 
-	stats, err := fetchRadarrStats(serverURL, apiKey)
+	start := time.Now()
+	stats, err := fetchRadarrStats(c.Request.Context(), serverURL, apiKey)
+	metrics.ObserveFetch("radarr", start, err)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
 	}
 
+	metrics.SetWidgetValues(widget.ID, widget.Name, widget.Type, map[string]float64{
+		"total_movies":        float64(stats.TotalMovies),
+		"downloaded_movies":   float64(stats.DownloadedMovies),
+		"missing_movies":      float64(stats.MissingMovies),
+		"queued_items":        float64(stats.QueuedItems),
+		"download_progress":   stats.DownloadProgress,
+		"total_storage_bytes": float64(stats.TotalStorage),
+		"free_storage_bytes":  float64(stats.FreeStorage),
+		"health_alerts":       float64(len(stats.HealthAlerts)),
+	})
+
 	c.JSON(http.StatusOK, stats)
 }
 
-func fetchRadarrStats(serverURL, apiKey string) (*RadarrStats, error) {
-	if serverURL[len(serverURL)-1] == '/' {
-		serverURL = serverURL[:len(serverURL)-1]
-	}
-
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		},
+func fetchRadarrStats(ctx context.Context, serverURL, apiKey string) (*RadarrStats, error) {
+	client, err := services.NewArrClient("radarr", serverURL, apiKey)
+	if err != nil {
+		return nil, err
 	}
 
 	stats := &RadarrStats{}
 
-	if systemStatus, err := fetchRadarrSystemStatus(client, serverURL, apiKey); err == nil {
+	var systemStatus RadarrSystemStatus
+	if err := client.Get(ctx, "system/status", nil, &systemStatus); err == nil {
 		stats.Version = systemStatus.Version
 	}
 
-	movies, err := fetchRadarrMovies(client, serverURL, apiKey)
-	if err != nil {
+	var movies []RadarrMovie
+	if err := client.Get(ctx, "movie", nil, &movies); err != nil {
 		return nil, fmt.Errorf("failed to fetch movies data: %v", err)
 	}
 
@@ -174,7 +174,8 @@ func fetchRadarrStats(serverURL, apiKey string) (*RadarrStats, error) {
 	stats.DownloadedMovies = downloadedMovies
 	stats.MissingMovies = missingMovies
 
-	if queue, err := fetchRadarrQueue(client, serverURL, apiKey); err == nil {
+	var queue RadarrQueue
+	if err := client.Get(ctx, "queue", nil, &queue); err == nil {
 		stats.QueuedItems = queue.TotalRecords
 
 		if len(queue.Records) > 0 {
@@ -191,192 +192,16 @@ func fetchRadarrStats(serverURL, apiKey string) (*RadarrStats, error) {
 		}
 	}
 
-	if diskSpaces, err := fetchRadarrDiskSpace(client, serverURL, apiKey); err == nil && len(diskSpaces) > 0 {
-		if len(diskSpaces) > 0 {
-			diskIndex := 0
-			if len(diskSpaces) > 1 {
-				for i, disk := range diskSpaces {
-					if disk.Path == "/movies" || disk.Label == "movies" {
-						diskIndex = i
-						break
-					}
-				}
-			}
-			stats.TotalStorage = diskSpaces[diskIndex].TotalSpace
-			stats.FreeStorage = diskSpaces[diskIndex].FreeSpace
-		}
+	if total, free, err := client.LibraryStorage(ctx); err == nil {
+		stats.TotalStorage = total
+		stats.FreeStorage = free
 	}
 
-	if healthChecks, err := fetchRadarrHealth(client, serverURL, apiKey); err == nil {
+	stats.HealthAlerts = []RadarrHealthCheck{}
+	var healthChecks []RadarrHealthCheck
+	if err := client.Get(ctx, "health", nil, &healthChecks); err == nil {
 		stats.HealthAlerts = healthChecks
-	} else {
-		stats.HealthAlerts = []RadarrHealthCheck{}
 	}
 
 	return stats, nil
 }
-
-func fetchRadarrSystemStatus(client *http.Client, serverURL, apiKey string) (*RadarrSystemStatus, error) {
-	url := fmt.Sprintf("%s/api/v3/system/status?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var status RadarrSystemStatus
-	if err := json.Unmarshal(body, &status); err != nil {
-		return nil, err
-	}
-
-	return &status, nil
-}
-
-func fetchRadarrMovies(client *http.Client, serverURL, apiKey string) ([]RadarrMovie, error) {
-	url := fmt.Sprintf("%s/api/v3/movie?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var movies []RadarrMovie
-	if err := json.Unmarshal(body, &movies); err != nil {
-		return nil, err
-	}
-
-	return movies, nil
-}
-
-func fetchRadarrQueue(client *http.Client, serverURL, apiKey string) (*RadarrQueue, error) {
-	url := fmt.Sprintf("%s/api/v3/queue?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var queue RadarrQueue
-	if err := json.Unmarshal(body, &queue); err != nil {
-		return nil, err
-	}
-
-	return &queue, nil
-}
-
-func fetchRadarrDiskSpace(client *http.Client, serverURL, apiKey string) ([]RadarrDiskSpace, error) {
-	url := fmt.Sprintf("%s/api/v3/diskspace?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var diskSpaces []RadarrDiskSpace
-	if err := json.Unmarshal(body, &diskSpaces); err != nil {
-		return nil, err
-	}
-
-	return diskSpaces, nil
-}
-
-func fetchRadarrHealth(client *http.Client, serverURL, apiKey string) ([]RadarrHealthCheck, error) {
-	url := fmt.Sprintf("%s/api/v3/health?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var healthChecks []RadarrHealthCheck
-	if err := json.Unmarshal(body, &healthChecks); err != nil {
-		return nil, err
-	}
-
-	return healthChecks, nil
-}
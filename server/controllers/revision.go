@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"dashboard-server/audit"
+	"dashboard-server/database"
+	"dashboard-server/models"
+	"dashboard-server/revisions"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func GetWidgetRevisions(c *gin.Context)     { listRevisions(c, audit.EntityWidget) }
+func GetWidgetRevision(c *gin.Context)      { getRevision(c, audit.EntityWidget) }
+func DiffWidgetRevisions(c *gin.Context)    { diffRevisions(c, audit.EntityWidget) }
+func RollbackWidget(c *gin.Context)         { rollback(c, audit.EntityWidget) }
+func GetDashboardRevisions(c *gin.Context)  { listRevisions(c, audit.EntityDashboard) }
+func GetDashboardRevision(c *gin.Context)   { getRevision(c, audit.EntityDashboard) }
+func DiffDashboardRevisions(c *gin.Context) { diffRevisions(c, audit.EntityDashboard) }
+func RollbackDashboard(c *gin.Context)      { rollback(c, audit.EntityDashboard) }
+
+func listRevisions(c *gin.Context, entityType string) {
+	entityID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var list []models.Revision
+	result := database.DB.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("version DESC").Find(&list)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	responses := []models.RevisionResponse{}
+	for _, revision := range list {
+		responses = append(responses, revision.ToResponse(false))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+func getRevision(c *gin.Context, entityType string) {
+	revision, ok := findRevision(c, entityType, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": revision.ToResponse(true)})
+}
+
+// diffRevisions compares ?from= and ?to= versions. to defaults to the
+// latest revision.
+func diffRevisions(c *gin.Context, entityType string) {
+	from, ok := findRevision(c, entityType, c.Query("from"))
+	if !ok {
+		return
+	}
+
+	toVersion := c.Query("to")
+	if toVersion == "" {
+		var latest models.Revision
+		if err := database.DB.Where("entity_type = ? AND entity_id = ?", entityType, from.EntityID).
+			Order("version DESC").First(&latest).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		toVersion = strconv.Itoa(latest.Version)
+	}
+
+	to, ok := findRevision(c, entityType, toVersion)
+	if !ok {
+		return
+	}
+
+	changes, err := revisions.Diff(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"from":    from.Version,
+		"to":      to.Version,
+		"changes": changes,
+	}})
+}
+
+func rollback(c *gin.Context, entityType string) {
+	revision, ok := findRevision(c, entityType, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	var data interface{}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if entityType == audit.EntityWidget {
+			widget, err := revisions.RollbackWidget(tx, c, revision)
+			if err != nil {
+				return err
+			}
+			data = widget.ToResponse()
+			return nil
+		}
+
+		dashboard, err := revisions.RollbackDashboard(tx, c, revision)
+		if err != nil {
+			return err
+		}
+		data = dashboard.ToResponse()
+		return nil
+	})
+
+	switch {
+	case errors.Is(err, revisions.ErrDashboardMissing):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"data": data})
+	}
+}
+
+func findRevision(c *gin.Context, entityType, versionParam string) (*models.Revision, bool) {
+	entityID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return nil, false
+	}
+
+	version, err := strconv.Atoi(versionParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision version"})
+		return nil, false
+	}
+
+	revision, err := revisions.Find(database.DB, entityType, uint(entityID), version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+	return revision, true
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"dashboard-server/database"
+	"dashboard-server/models"
+	"dashboard-server/revisions"
+)
+
+func TestDashboardRevisionHidesGlancesPassword(t *testing.T) {
+	dashboard := setupDB(t)
+	glancesConfig := `{"url":"http://glances:61208","username":"admin","password":"glances-s3cret"}`
+	dashboard.GlancesConfig = glancesConfig
+	if err := database.DB.Save(&dashboard).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := revisions.SaveDashboard(database.DB, nil, dashboard.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var response struct {
+		Data struct {
+			Snapshot struct {
+				GlancesConfig string `json:"glances_config"`
+			} `json:"snapshot"`
+		} `json:"data"`
+	}
+	w := serve(t, http.MethodGet, "/dashboards/:id/revisions/:version", fmt.Sprintf("/dashboards/%d/revisions/1", dashboard.ID), nil, &response, GetDashboardRevision)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	if strings.Contains(w.Body.String(), "glances-s3cret") {
+		t.Errorf("revision response leaks the Glances password: %s", w.Body)
+	}
+	var shown map[string]interface{}
+	if err := json.Unmarshal([]byte(response.Data.Snapshot.GlancesConfig), &shown); err != nil {
+		t.Fatalf("glances_config %q: %v", response.Data.Snapshot.GlancesConfig, err)
+	}
+	if shown["url"] != "http://glances:61208" || shown["username"] != "admin" {
+		t.Errorf("glances_config = %v, want url and username kept", shown)
+	}
+
+	// The stored snapshot keeps the password so a rollback still works.
+	if err := database.DB.Model(&dashboard).Update("glances_config", `{"url":"http://other:61208"}`).Error; err != nil {
+		t.Fatal(err)
+	}
+	w = serve(t, http.MethodPost, "/dashboards/:id/revisions/:version/rollback", fmt.Sprintf("/dashboards/%d/revisions/1/rollback", dashboard.ID), nil, nil, RollbackDashboard)
+	if w.Code != http.StatusOK {
+		t.Fatalf("rollback: status %d: %s", w.Code, w.Body)
+	}
+	var restored models.Dashboard
+	if err := database.DB.First(&restored, dashboard.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if restored.GlancesConfig != glancesConfig {
+		t.Errorf("rolled back glances_config = %q, want %q", restored.GlancesConfig, glancesConfig)
+	}
+}
@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dashboard-server/audit"
+	"dashboard-server/database"
+	"dashboard-server/middleware"
+	"dashboard-server/models"
+	"dashboard-server/shares"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CreateShareRequest struct {
+	Name          string            `json:"name"`
+	ExpiresIn     string            `json:"expires_in"`
+	ExpiresAt     *time.Time        `json:"expires_at"`
+	HiddenWidgets models.IDList     `json:"hidden_widgets"`
+	HiddenFields  models.FieldRules `json:"hidden_fields"`
+}
+
+type ShareLinkResponse struct {
+	models.ShareLink
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// shareProxies maps the integration segment of a share URL to the widget
+// type it serves and the handler that fetches its data. The paths mirror the
+// authenticated API so the UI can swap its base URL.
+var shareProxies = map[string]struct {
+	widgetType string
+	handler    gin.HandlerFunc
+}{
+	"adguard":      {"adguard-home", ProxyAdGuardStats},
+	"sonarr":       {"sonarr", ProxySonarrStats},
+	"radarr":       {"radarr", ProxyRadarrStats},
+	"lidarr":       {"lidarr", ProxyLidarrStats},
+	"transmission": {"transmission", ProxyTransmissionStats},
+	"qbittorrent":  {"qbittorrent", ProxyQBittorrentStats},
+	"immich":       {"immich", ProxyImmichStats},
+	"prowlarr":     {"prowlarr", ProxyProwlarrStats},
+	"docker":       {"docker", ProxyDockerStats},
+	"kubernetes":   {"kubernetes", ProxyKubernetesStats},
+	"proxmox":      {"proxmox", ProxyProxmoxStats},
+}
+
+func shareAuditFields(link *models.ShareLink) map[string]interface{} {
+	fields := map[string]interface{}{
+		"dashboard_id": link.DashboardID,
+		"name":         link.Name,
+	}
+	if link.ExpiresAt != nil {
+		fields["expires_at"] = link.ExpiresAt.Format(time.RFC3339)
+	}
+	if link.RevokedAt != nil {
+		fields["revoked_at"] = link.RevokedAt.Format(time.RFC3339)
+	}
+	return fields
+}
+
+func newShareLinkResponse(c *gin.Context, link *models.ShareLink) (ShareLinkResponse, error) {
+	token, err := shares.Token(database.DB, link)
+	if err != nil {
+		return ShareLinkResponse{}, err
+	}
+	return ShareLinkResponse{
+		ShareLink: *link,
+		Token:     token,
+		URL:       middleware.ExternalURL(c, "/api/v1/share/"+token+"/dashboard"),
+	}, nil
+}
+
+func CreateShareLink(c *gin.Context) {
+	id := c.Param("id")
+
+	var request CreateShareRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var dashboard models.Dashboard
+	if err := database.DB.First(&dashboard, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dashboard not found"})
+		return
+	}
+
+	nonce, err := shares.NewNonce()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	link := models.ShareLink{
+		DashboardID:   dashboard.ID,
+		Name:          request.Name,
+		Nonce:         nonce,
+		HiddenWidgets: request.HiddenWidgets,
+		HiddenFields:  request.HiddenFields,
+		ExpiresAt:     request.ExpiresAt,
+		CreatedBy:     c.GetString("user"),
+	}
+	if request.ExpiresIn != "" {
+		duration, err := time.ParseDuration(request.ExpiresIn)
+		if err != nil || duration <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expires_in, expected a duration such as 720h"})
+			return
+		}
+		expiresAt := time.Now().Add(duration)
+		link.ExpiresAt = &expiresAt
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&link).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, models.AuditActionCreate, audit.EntityShare, link.ID, nil, shareAuditFields(&link))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := newShareLinkResponse(c, &link)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": response})
+}
+
+func GetShareLinks(c *gin.Context) {
+	id := c.Param("id")
+
+	var links []models.ShareLink
+	if err := database.DB.Where("dashboard_id = ?", id).Order("id DESC").Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	responses := []ShareLinkResponse{}
+	for i := range links {
+		response, err := newShareLinkResponse(c, &links[i])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		responses = append(responses, response)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": responses})
+}
+
+func RevokeShareLink(c *gin.Context) {
+	id := c.Param("id")
+
+	var link models.ShareLink
+	if err := database.DB.First(&link, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+		return
+	}
+	if link.RevokedAt != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Share link already revoked"})
+		return
+	}
+
+	before := shareAuditFields(&link)
+	now := time.Now()
+	link.RevokedAt = &now
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&link).Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, c, models.AuditActionUpdate, audit.EntityShare, link.ID, before, shareAuditFields(&link))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share link revoked"})
+}
+
+// RequireShareToken authorises public share requests by the :token path
+// parameter and counts each use.
+func RequireShareToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		link, err := shares.Authorize(database.DB, c.Param("token"))
+		if err != nil {
+			if errors.Is(err, shares.ErrInvalidToken) {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		if err := shares.RecordUse(database.DB, link, c.ClientIP()); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set("share_link", link)
+		c.Next()
+	}
+}
+
+func GetSharedDashboard(c *gin.Context) {
+	link := c.MustGet("share_link").(*models.ShareLink)
+
+	var dashboard models.Dashboard
+	result := database.DB.Preload("Widgets").Preload("Sections", orderByPosition).First(&dashboard, link.DashboardID)
+	if result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dashboard not found"})
+		return
+	}
+
+	visible := dashboard.Widgets[:0]
+	for _, widget := range dashboard.Widgets {
+		if !link.HiddenWidgets.Contains(widget.ID) && widget.IsEnabled {
+			visible = append(visible, widget)
+		}
+	}
+	dashboard.Widgets = visible
+
+	response := dashboard.ToResponse()
+	// The Glances config carries the Glances password.
+	response.GlancesConfig = ""
+	for i := range response.Widgets {
+		fields := link.FieldsFor(response.Widgets[i].ID)
+		shares.RemoveFields(response.Widgets[i].Config, fields)
+		shares.RemoveFields(response.Widgets[i].LastState, fields)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+// ProxySharedWidget serves widget data through a share link by running the
+// normal proxy handler and stripping hidden fields from its response.
+func ProxySharedWidget(c *gin.Context) {
+	link := c.MustGet("share_link").(*models.ShareLink)
+
+	proxy, ok := shareProxies[c.Param("integration")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown integration"})
+		return
+	}
+
+	widgetID, err := strconv.ParseUint(c.Param("widget_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid widget ID"})
+		return
+	}
+
+	var widget models.Widget
+	err = database.DB.Where("dashboard_id = ?", link.DashboardID).First(&widget, widgetID).Error
+	if err != nil || link.HiddenWidgets.Contains(widget.ID) || !widget.IsEnabled || widget.Type != proxy.widgetType {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Widget not found"})
+		return
+	}
+
+	recorder := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+	c.Writer = recorder
+	proxy.handler(c)
+	c.Writer = recorder.ResponseWriter
+
+	body := recorder.body.Bytes()
+	if fields := link.FieldsFor(widget.ID); len(fields) > 0 && recorder.status < 300 {
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err == nil {
+			shares.RemoveFields(data, fields)
+			if filtered, err := json.Marshal(data); err == nil {
+				body = filtered
+			}
+		}
+	}
+
+	c.Data(recorder.status, "application/json; charset=utf-8", body)
+}
+
+// bufferedWriter holds a handler's response so it can be rewritten before
+// reaching the client.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
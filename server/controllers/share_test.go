@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"dashboard-server/database"
+	"dashboard-server/models"
+)
+
+func TestSharedDashboardHidesCredentials(t *testing.T) {
+	dashboard := setupDB(t)
+	dashboard.GlancesConfig = `{"url":"http://glances:61208","username":"admin","password":"glances-s3cret"}`
+	if err := database.DB.Save(&dashboard).Error; err != nil {
+		t.Fatal(err)
+	}
+	createTestWidget(t, models.Widget{
+		DashboardID: dashboard.ID,
+		Name:        "Sonarr",
+		Type:        "sonarr",
+		IsEnabled:   true,
+		Config: models.JSON{
+			"serverUrl": "http://sonarr:8989",
+			"apiKey":    "sonarr-s3cret",
+			"auth":      map[string]interface{}{"password": "nested-s3cret"},
+		},
+	})
+	createTestWidget(t, models.Widget{
+		DashboardID: dashboard.ID,
+		Name:        "Proxmox",
+		Type:        "proxmox",
+		IsEnabled:   true,
+		Config: models.JSON{
+			"serverUrl":   "https://pve:8006",
+			"tokenId":     "root@pam!dash",
+			"tokenSecret": "pve-s3cret",
+		},
+	})
+
+	var created struct {
+		Data ShareLinkResponse `json:"data"`
+	}
+	w := serve(t, http.MethodPost, "/dashboards/:id/shares", fmt.Sprintf("/dashboards/%d/shares", dashboard.ID),
+		map[string]string{"name": "Hallway"}, &created, CreateShareLink)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create share: status %d: %s", w.Code, w.Body)
+	}
+
+	var shared struct {
+		Data models.DashboardResponse `json:"data"`
+	}
+	w = serve(t, http.MethodGet, "/share/:token/dashboard", "/share/"+created.Data.Token+"/dashboard",
+		nil, &shared, RequireShareToken(), GetSharedDashboard)
+	if w.Code != http.StatusOK {
+		t.Fatalf("shared dashboard: status %d: %s", w.Code, w.Body)
+	}
+
+	if strings.Contains(w.Body.String(), "s3cret") {
+		t.Errorf("shared dashboard leaks a credential: %s", w.Body)
+	}
+	if shared.Data.GlancesConfig != "" {
+		t.Errorf("glances_config = %q, want it blank", shared.Data.GlancesConfig)
+	}
+	if len(shared.Data.Widgets) != 2 {
+		t.Fatalf("got %d widgets, want 2", len(shared.Data.Widgets))
+	}
+	if shared.Data.Widgets[0].Config["serverUrl"] != "http://sonarr:8989" {
+		t.Errorf("non-secret config was removed: %v", shared.Data.Widgets[0].Config)
+	}
+}
@@ -1,16 +1,17 @@
 package controllers
 
 import (
-	"crypto/tls"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	"dashboard-server/database"
+	"dashboard-server/metrics"
 	"dashboard-server/models"
+	"dashboard-server/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -43,13 +44,6 @@ type SonarrQueueRecord struct {
 	Sizeleft int64 `json:"sizeleft"`
 }
 
-type SonarrDiskSpace struct {
-	Path       string `json:"path"`
-	Label      string `json:"label"`
-	FreeSpace  int64  `json:"freeSpace"`
-	TotalSpace int64  `json:"totalSpace"`
-}
-
 type SonarrHealthCheck struct {
 	Source  string `json:"source"`
 	Type    string `json:"type"`
@@ -72,7 +66,7 @@ type SonarrStats struct {
 func TestSonarrConnection(c *gin.Context) {
 	var config SonarrTestConfig
 	if err := c.ShouldBindJSON(&config); err != nil {
-		fmt.Println("Error binding JSON:", err)
+		slog.DebugContext(c.Request.Context(), "Invalid test configuration", "integration", "sonarr", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid configuration: " + err.Error()})
 		return
 	}
@@ -87,7 +81,7 @@ func TestSonarrConnection(c *gin.Context) {
 		return
 	}
 
-	stats, err := fetchSonarrStats(config.ServerURL, config.ApiKey)
+	stats, err := fetchSonarrStats(c.Request.Context(), config.ServerURL, config.ApiKey)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
@@ -128,37 +122,43 @@ func ProxySonarrStats(c *gin.Context) {
 		return
 	}
 
-	stats, err := fetchSonarrStats(serverURL, apiKey)
+	start := time.Now()
+	stats, err := fetchSonarrStats(c.Request.Context(), serverURL, apiKey)
+	metrics.ObserveFetch("sonarr", start, err)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
 	}
 
+	metrics.SetWidgetValues(widget.ID, widget.Name, widget.Type, map[string]float64{
+		"total_series":        float64(stats.TotalSeries),
+		"total_episodes":      float64(stats.TotalEpisodes),
+		"missing_episodes":    float64(stats.MissingEpisodes),
+		"queued_items":        float64(stats.QueuedItems),
+		"download_progress":   stats.DownloadProgress,
+		"total_storage_bytes": float64(stats.TotalStorage),
+		"free_storage_bytes":  float64(stats.FreeStorage),
+		"health_alerts":       float64(len(stats.HealthAlerts)),
+	})
+
 	c.JSON(http.StatusOK, stats)
 }
 
-func fetchSonarrStats(serverURL, apiKey string) (*SonarrStats, error) {
-	if serverURL[len(serverURL)-1] == '/' {
-		serverURL = serverURL[:len(serverURL)-1]
-	}
-
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		},
+func fetchSonarrStats(ctx context.Context, serverURL, apiKey string) (*SonarrStats, error) {
+	client, err := services.NewArrClient("sonarr", serverURL, apiKey)
+	if err != nil {
+		return nil, err
 	}
 
 	stats := &SonarrStats{}
 
-	if systemStatus, err := fetchSonarrSystemStatus(client, serverURL, apiKey); err == nil {
+	var systemStatus SonarrSystemStatus
+	if err := client.Get(ctx, "system/status", nil, &systemStatus); err == nil {
 		stats.Version = systemStatus.Version
 	}
 
-	series, err := fetchSonarrSeries(client, serverURL, apiKey)
-	if err != nil {
+	var series []SonarrSeries
+	if err := client.Get(ctx, "series", nil, &series); err != nil {
 		return nil, fmt.Errorf("failed to fetch series data: %v", err)
 	}
 
@@ -177,7 +177,8 @@ func fetchSonarrStats(serverURL, apiKey string) (*SonarrStats, error) {
 	stats.TotalEpisodes = totalEpisodes
 	stats.MissingEpisodes = missingEpisodes
 
-	if queue, err := fetchSonarrQueue(client, serverURL, apiKey); err == nil {
+	var queue SonarrQueue
+	if err := client.Get(ctx, "queue", nil, &queue); err == nil {
 		stats.QueuedItems = queue.TotalRecords
 
 		if len(queue.Records) > 0 {
@@ -194,181 +195,16 @@ func fetchSonarrStats(serverURL, apiKey string) (*SonarrStats, error) {
 		}
 	}
 
-	if diskSpaces, err := fetchSonarrDiskSpace(client, serverURL, apiKey); err == nil && len(diskSpaces) > 0 {
-		stats.TotalStorage = diskSpaces[3].TotalSpace
-		stats.FreeStorage = diskSpaces[3].FreeSpace
+	if total, free, err := client.LibraryStorage(ctx); err == nil {
+		stats.TotalStorage = total
+		stats.FreeStorage = free
 	}
 
-	if healthChecks, err := fetchSonarrHealth(client, serverURL, apiKey); err == nil {
+	stats.HealthAlerts = []SonarrHealthCheck{}
+	var healthChecks []SonarrHealthCheck
+	if err := client.Get(ctx, "health", nil, &healthChecks); err == nil {
 		stats.HealthAlerts = healthChecks
-	} else {
-		stats.HealthAlerts = []SonarrHealthCheck{}
 	}
 
 	return stats, nil
 }
-
-func fetchSonarrSystemStatus(client *http.Client, serverURL, apiKey string) (*SonarrSystemStatus, error) {
-	url := fmt.Sprintf("%s/api/v3/system/status?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var status SonarrSystemStatus
-	if err := json.Unmarshal(body, &status); err != nil {
-		return nil, err
-	}
-
-	return &status, nil
-}
-
-func fetchSonarrSeries(client *http.Client, serverURL, apiKey string) ([]SonarrSeries, error) {
-	url := fmt.Sprintf("%s/api/v3/series?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var series []SonarrSeries
-	if err := json.Unmarshal(body, &series); err != nil {
-		return nil, err
-	}
-
-	return series, nil
-}
-
-func fetchSonarrQueue(client *http.Client, serverURL, apiKey string) (*SonarrQueue, error) {
-	url := fmt.Sprintf("%s/api/v3/queue?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var queue SonarrQueue
-	if err := json.Unmarshal(body, &queue); err != nil {
-		return nil, err
-	}
-
-	return &queue, nil
-}
-
-func fetchSonarrDiskSpace(client *http.Client, serverURL, apiKey string) ([]SonarrDiskSpace, error) {
-	url := fmt.Sprintf("%s/api/v3/diskspace?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var diskSpaces []SonarrDiskSpace
-	if err := json.Unmarshal(body, &diskSpaces); err != nil {
-		return nil, err
-	}
-
-	return diskSpaces, nil
-}
-
-func fetchSonarrHealth(client *http.Client, serverURL, apiKey string) ([]SonarrHealthCheck, error) {
-	url := fmt.Sprintf("%s/api/v3/health?apikey=%s", serverURL, apiKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("connection failed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var healthChecks []SonarrHealthCheck
-	if err := json.Unmarshal(body, &healthChecks); err != nil {
-		return nil, err
-	}
-
-	return healthChecks, nil
-}
@@ -2,7 +2,7 @@ package controllers
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,7 +11,9 @@ import (
 	"time"
 
 	"dashboard-server/database"
+	"dashboard-server/metrics"
 	"dashboard-server/models"
+	"dashboard-server/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -116,7 +118,9 @@ func ProxyTransmissionStats(c *gin.Context) {
 		transmissionConfig.MaxUploadSpeed = int(maxUpload)
 	}
 
-	stats, err := fetchTransmissionStats(transmissionConfig)
+	start := time.Now()
+	stats, err := fetchTransmissionStats(c.Request.Context(), transmissionConfig)
+	metrics.ObserveFetch("transmission", start, err)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -125,6 +129,15 @@ func ProxyTransmissionStats(c *gin.Context) {
 		return
 	}
 
+	metrics.SetWidgetValues(widget.ID, widget.Name, widget.Type, map[string]float64{
+		"downloading_torrents": float64(stats.DownloadingTorrents),
+		"seeding_torrents":     float64(stats.SeedingTorrents),
+		"error_torrents":       float64(stats.ErrorTorrents),
+		"total_torrents":       float64(stats.TotalTorrents),
+		"download_speed_bytes": stats.DownloadSpeed,
+		"upload_speed_bytes":   stats.UploadSpeed,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    stats,
@@ -138,7 +151,7 @@ func TestTransmissionConnection(c *gin.Context) {
 		return
 	}
 
-	_, err := fetchTransmissionStats(config)
+	_, err := fetchTransmissionStats(c.Request.Context(), config)
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
 			"success": false,
@@ -153,11 +166,8 @@ func TestTransmissionConnection(c *gin.Context) {
 	})
 }
 
-func fetchTransmissionStats(config TransmissionConfig) (*TransmissionStats, error) {
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
-	client := &http.Client{Transport: tr, Timeout: 30 * time.Second}
+func fetchTransmissionStats(ctx context.Context, config TransmissionConfig) (*TransmissionStats, error) {
+	client := services.NewHTTPClient(30*time.Second, true)
 
 	baseURL := config.ServerURL
 	if config.RPCPath == "" {
@@ -165,12 +175,12 @@ func fetchTransmissionStats(config TransmissionConfig) (*TransmissionStats, erro
 	}
 	url := baseURL + config.RPCPath
 
-	sessionID, err := getTransmissionSessionID(client, url, config.Username, config.Password)
+	sessionID, err := getTransmissionSessionID(ctx, client, url, config.Username, config.Password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session ID: %v", err)
 	}
 
-	torrents, err := getTransmissionTorrents(client, url, config.Username, config.Password, sessionID)
+	torrents, err := getTransmissionTorrents(ctx, client, url, config.Username, config.Password, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get torrents: %v", err)
 	}
@@ -196,7 +206,7 @@ func fetchTransmissionStats(config TransmissionConfig) (*TransmissionStats, erro
 
 	return stats, nil
 }
-func getTransmissionSessionID(client *http.Client, url, username, password string) (string, error) {
+func getTransmissionSessionID(ctx context.Context, client *http.Client, url, username, password string) (string, error) {
 	reqBody := TransmissionRPCRequest{
 		Method: "session-get",
 		Arguments: map[string]interface{}{
@@ -209,7 +219,7 @@ func getTransmissionSessionID(client *http.Client, url, username, password strin
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", err
 	}
@@ -239,7 +249,7 @@ func getTransmissionSessionID(client *http.Client, url, username, password strin
 	return "", fmt.Errorf("unexpected response status: %d", resp.StatusCode)
 }
 
-func getTransmissionTorrents(client *http.Client, url, username, password, sessionID string) ([]Torrent, error) {
+func getTransmissionTorrents(ctx context.Context, client *http.Client, url, username, password, sessionID string) ([]Torrent, error) {
 	reqBody := TransmissionRPCRequest{
 		Method: "torrent-get",
 		Arguments: TorrentGetArguments{
@@ -252,7 +262,7 @@ func getTransmissionTorrents(client *http.Client, url, username, password, sessi
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
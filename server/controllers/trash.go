@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"dashboard-server/database"
+	"dashboard-server/trash"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func GetTrash(c *gin.Context) {
+	items, err := trash.List(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": items})
+}
+
+func RestoreDashboard(c *gin.Context) {
+	id, ok := trashID(c)
+	if !ok {
+		return
+	}
+
+	var data interface{}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		dashboard, err := trash.RestoreDashboard(tx, c, id)
+		if err != nil {
+			return err
+		}
+		data = dashboard.ToResponse()
+		return nil
+	})
+	respondTrash(c, err, data)
+}
+
+func RestoreWidget(c *gin.Context) {
+	id, ok := trashID(c)
+	if !ok {
+		return
+	}
+
+	var data interface{}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		widget, err := trash.RestoreWidget(tx, c, id)
+		if err != nil {
+			return err
+		}
+		data = widget.ToResponse()
+		return nil
+	})
+	respondTrash(c, err, data)
+}
+
+func PurgeDashboard(c *gin.Context) {
+	id, ok := trashID(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return trash.PurgeDashboard(tx, c, id)
+	})
+	respondTrash(c, err, nil)
+}
+
+func PurgeWidget(c *gin.Context) {
+	id, ok := trashID(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return trash.PurgeWidget(tx, c, id)
+	})
+	respondTrash(c, err, nil)
+}
+
+func trashID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func respondTrash(c *gin.Context, err error, data interface{}) {
+	switch {
+	case errors.Is(err, trash.ErrNotInTrash):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, trash.ErrDashboardDeleted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	case data == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "Permanently deleted"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"data": data})
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"net/http"
 	"strconv"
 
+	"dashboard-server/audit"
 	"dashboard-server/models"
 	"dashboard-server/database"
+	"dashboard-server/metrics"
+	"dashboard-server/revisions"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 func GetWidgets(c *gin.Context) {
 	dashboardID := c.Param("id")
@@ -43,7 +47,7 @@ func GetWidget(c *gin.Context) {
 func CreateWidget(c *gin.Context) {
 	dashboardID := c.Param("id")
 
-	var widget models.Widget
+	widget := models.Widget{IsEnabled: true}
 	if err := c.ShouldBindJSON(&widget); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -55,6 +59,7 @@ func CreateWidget(c *gin.Context) {
 		return
 	}
 	widget.DashboardID = uint(id)
+	widget.Source = ""
 
 	var dashboard models.Dashboard
 	if err := database.DB.First(&dashboard, widget.DashboardID).Error; err != nil {
@@ -62,9 +67,22 @@ func CreateWidget(c *gin.Context) {
 		return
 	}
 
-	result := database.DB.Create(&widget)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := fitWidget(tx, &widget); err != nil {
+			return err
+		}
+		if err := tx.Create(&widget).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, c, models.AuditActionCreate, audit.EntityWidget, widget.ID, nil, audit.WidgetFields(&widget)); err != nil {
+			return err
+		}
+		return revisions.SaveWidget(tx, c, &widget)
+	})
+	if err != nil {
+		if !respondLayoutError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -79,13 +97,40 @@ func UpdateWidget(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Widget not found"})
 		return
 	}
+	before := audit.WidgetFields(&widget)
+	placement := widgetPlacement(&widget)
+	source := widget.Source
 
 	if err := c.ShouldBindJSON(&widget); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	widget.Source = source
+	if widget.IsEnabled {
+		widget.DisabledBySource = false
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if widgetPlacement(&widget) != placement {
+			if err := fitWidget(tx, &widget); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(&widget).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, c, models.AuditActionUpdate, audit.EntityWidget, widget.ID, before, audit.WidgetFields(&widget)); err != nil {
+			return err
+		}
+		return revisions.SaveWidget(tx, c, &widget)
+	})
+	if err != nil {
+		if !respondLayoutError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
 
-	database.DB.Save(&widget)
 	c.JSON(http.StatusOK, gin.H{"data": widget.ToResponse()})
 }
 
@@ -122,6 +167,20 @@ func DeleteWidget(c *gin.Context) {
 		return
 	}
 
-	database.DB.Delete(&widget)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&widget).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, c, models.AuditActionDelete, audit.EntityWidget, widget.ID, audit.WidgetFields(&widget), nil); err != nil {
+			return err
+		}
+		return revisions.SaveDashboard(tx, c, widget.DashboardID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	metrics.DeleteWidgetValues(widget.ID)
 	c.JSON(http.StatusOK, gin.H{"message": "Widget deleted successfully"})
 }
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"dashboard-server/database"
+	"dashboard-server/models"
+)
+
+func TestCreateWidgetEnabled(t *testing.T) {
+	dashboard := setupDB(t)
+	route, path := "/dashboards/:id/widgets", fmt.Sprintf("/dashboards/%d/widgets", dashboard.ID)
+
+	tests := []struct {
+		name string
+		body map[string]interface{}
+		want bool
+	}{
+		{"omitted", map[string]interface{}{"name": "Sonarr", "type": "sonarr"}, true},
+		{"enabled", map[string]interface{}{"name": "Radarr", "type": "radarr", "is_enabled": true}, true},
+		{"disabled", map[string]interface{}{"name": "Lidarr", "type": "lidarr", "is_enabled": false}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response struct {
+				Data models.WidgetResponse `json:"data"`
+			}
+			w := serve(t, http.MethodPost, route, path, tt.body, &response, CreateWidget)
+			if w.Code != http.StatusCreated {
+				t.Fatalf("status %d: %s", w.Code, w.Body)
+			}
+
+			var saved models.Widget
+			database.DB.First(&saved, response.Data.ID)
+			if saved.IsEnabled != tt.want || response.Data.IsEnabled != tt.want {
+				t.Errorf("is_enabled stored %v, returned %v, want %v", saved.IsEnabled, response.Data.IsEnabled, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,16 @@
 package database
 
 import (
+	"context"
+	"dashboard-server/glances"
+	"dashboard-server/layout"
+	"dashboard-server/logging"
 	"dashboard-server/models"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"gorm.io/driver/sqlite"
@@ -13,41 +20,122 @@ import (
 
 var DB *gorm.DB
 
-func InitDatabase() {
-	var err error
+var migrated atomic.Bool
+
+var migratedModels = []interface{}{&models.Dashboard{}, &models.Widget{}, &models.AuditLog{}, &models.Revision{}, &models.Section{}, &models.ShareLink{}, &models.AppSecret{}, &models.UserPreference{}, &models.MonitoredHost{}}
 
+func InitDatabase() {
 	dbPath := os.Getenv("DB_PATH")
 	if dbPath == "" {
 		dbPath = "./dashboard.db"
 	}
 
-	DB, err = gorm.Open(sqlite.Open(dbPath), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+	var err error
+	DB, err = Open(dbPath)
+	if err != nil {
+		logging.Fatal("Failed to open database", "error", err)
+	}
+	migrated.Store(true)
+
+	if err := layout.Backfill(DB); err != nil {
+		slog.Warn("Failed to place widgets without a stored layout", "error", err)
+	}
+
+	if err := glances.ImportHosts(DB); err != nil {
+		slog.Warn("Failed to import Glances hosts from dashboards", "error", err)
+	}
+
+	var dashboard models.Dashboard
+	result := DB.First(&dashboard)
+
+	if result.Error != nil {
+		dashboard = models.Dashboard{Name: "Default Dashboard"}
+		DB.Create(&dashboard)
+		slog.Info("Created default dashboard")
+	}
+
+	slog.Info("Database connected and migrated successfully", "path", dbPath)
+}
+
+// Open connects to the SQLite database at path and migrates every model,
+// without the startup backfills and default data of InitDatabase.
+func Open(path string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{
+		Logger: logger.NewSlogLogger(slog.Default(), logger.Config{
+			SlowThreshold:             200 * time.Millisecond,
+			LogLevel:                  gormLogLevel(),
+			IgnoreRecordNotFoundError: true,
+			ParameterizedQueries:      true,
+		}),
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
 
-	sqlDB, err := DB.DB()
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
 
 	sqlDB.SetMaxIdleConns(5)
 	sqlDB.SetMaxOpenConns(50)
 	sqlDB.SetConnMaxLifetime(time.Minute * 15)
 
-	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+	if err := db.AutoMigrate(migratedModels...); err != nil {
+		return nil, fmt.Errorf("failed to migrate: %w", err)
+	}
+	return db, nil
+}
+
+// gormLogLevel maps DB_LOG_LEVEL to GORM's levels. Queries are always logged
+// without their bound values so widget secrets never reach the logs.
+func gormLogLevel() logger.LogLevel {
+	switch os.Getenv("DB_LOG_LEVEL") {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "info":
+		return logger.Info
+	default:
+		return logger.Warn
+	}
+}
+
+// Ready reports whether the database is reachable and fully migrated.
+func Ready(ctx context.Context) error {
+	if DB == nil {
+		return errors.New("database not initialized")
 	}
 
-	err = DB.AutoMigrate(&models.Dashboard{}, &models.Widget{})
+	sqlDB, err := DB.DB()
 	if err != nil {
-		log.Fatal("Failed to migrate database:", err)
+		return err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("database unreachable: %w", err)
 	}
 
-	var dashboard models.Dashboard
-	result := DB.First(&dashboard)
+	if !migrated.Load() {
+		return errors.New("database migrations have not completed")
+	}
+	for _, model := range migratedModels {
+		if !DB.WithContext(ctx).Migrator().HasTable(model) {
+			return fmt.Errorf("missing table for %T", model)
+		}
+	}
 
-	if result.Error != nil {
-		dashboard = models.Dashboard{Name: "Default Dashboard"}
-		DB.Create(&dashboard)
-		log.Println("Created default dashboard")
+	return nil
+}
+
+func Close() error {
+	if DB == nil {
+		return nil
 	}
 
-	log.Println("Database connected and migrated successfully")
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
 }
@@ -0,0 +1,247 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"dashboard-server/audit"
+	"dashboard-server/config"
+	"dashboard-server/layout"
+	"dashboard-server/models"
+	"dashboard-server/revisions"
+
+	"gorm.io/gorm"
+)
+
+// DefaultDashboard receives discovered widgets that name no dashboard when
+// no dashboard exists yet.
+const DefaultDashboard = "Discovered"
+
+// Service is a widget described outside the dashboard, by container labels
+// or annotations. Source stays the same across syncs so the same widget is
+// updated each time.
+type Service struct {
+	Source    string
+	Name      string
+	Type      string
+	Dashboard string
+	Config    models.JSON
+}
+
+// Sync creates or updates a widget for each service and disables the
+// provider's widgets whose service is gone. A widget is re-enabled when its
+// service returns, unless the user disabled it; widgets the user moved to
+// the trash are left there.
+func Sync(db *gorm.DB, provider string, services []Service) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var existing []models.Widget
+		if err := tx.Unscoped().Where("source LIKE ?", provider+":%").Find(&existing).Error; err != nil {
+			return err
+		}
+		bySource := make(map[string]*models.Widget, len(existing))
+		for i := range existing {
+			bySource[existing[i].Source] = &existing[i]
+		}
+
+		seen := make(map[string]bool, len(services))
+		for _, service := range services {
+			seen[service.Source] = true
+
+			widget := bySource[service.Source]
+			if widget != nil && widget.DeletedAt.Valid {
+				continue
+			}
+			if err := apply(tx, widget, service); err != nil {
+				return fmt.Errorf("failed to sync %s: %w", service.Source, err)
+			}
+		}
+
+		for i := range existing {
+			widget := &existing[i]
+			if seen[widget.Source] || widget.DeletedAt.Valid || !widget.IsEnabled {
+				continue
+			}
+			before := audit.WidgetFields(widget)
+			widget.IsEnabled = false
+			widget.DisabledBySource = true
+			if err := save(tx, models.AuditActionUpdate, before, widget); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func apply(tx *gorm.DB, widget *models.Widget, service Service) error {
+	dashboardID, err := dashboardFor(tx, service.Dashboard)
+	if err != nil {
+		return err
+	}
+
+	if widget == nil {
+		widget = &models.Widget{
+			DashboardID: dashboardID,
+			Name:        service.Name,
+			Type:        service.Type,
+			Config:      service.Config.Clone(),
+			IsEnabled:   true,
+			Source:      service.Source,
+		}
+		if err := layout.PlaceInDashboard(tx, widget); err != nil {
+			return err
+		}
+		return save(tx, models.AuditActionCreate, nil, widget)
+	}
+
+	before := audit.WidgetFields(widget)
+	widget.Name = service.Name
+	widget.Type = service.Type
+	if widget.DisabledBySource {
+		widget.IsEnabled = true
+		widget.DisabledBySource = false
+	}
+	if widget.Config == nil {
+		widget.Config = models.JSON{}
+	}
+	for key, value := range service.Config {
+		widget.Config[key] = value
+	}
+	if widget.DashboardID != dashboardID {
+		widget.DashboardID = dashboardID
+		widget.SectionID = nil
+		widget.Layout = nil
+		if err := layout.PlaceInDashboard(tx, widget); err != nil {
+			return err
+		}
+	}
+
+	if len(audit.Diff(before, audit.WidgetFields(widget))) == 0 {
+		return nil
+	}
+	return save(tx, models.AuditActionUpdate, before, widget)
+}
+
+func save(tx *gorm.DB, action string, before map[string]interface{}, widget *models.Widget) error {
+	if err := tx.Save(widget).Error; err != nil {
+		return err
+	}
+	if err := audit.RecordSystem(tx, action, audit.EntityWidget, widget.ID, before, audit.WidgetFields(widget)); err != nil {
+		return err
+	}
+	return revisions.SaveWidget(tx, nil, widget)
+}
+
+// dashboardFor finds a dashboard by name, creating it if needed. Without a
+// name the first dashboard is used.
+func dashboardFor(tx *gorm.DB, name string) (uint, error) {
+	var dashboard models.Dashboard
+	query := tx.Order("id")
+	if name != "" {
+		query = query.Where("name = ?", name)
+	}
+	err := query.First(&dashboard).Error
+	if err == nil {
+		return dashboard.ID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	if name == "" {
+		name = DefaultDashboard
+	}
+	dashboard = models.Dashboard{Name: name}
+	if err := tx.Omit("Sections").Create(&dashboard).Error; err != nil {
+		return 0, err
+	}
+	if err := audit.RecordSystem(tx, models.AuditActionCreate, audit.EntityDashboard, dashboard.ID, nil, audit.DashboardFields(&dashboard)); err != nil {
+		return 0, err
+	}
+	return dashboard.ID, revisions.SaveDashboard(tx, nil, dashboard.ID)
+}
+
+// ParseLabels turns labels or annotations starting with prefix into a
+// service. Labels that name no type are not services. Credentials must be
+// given as <prefix><field>.env=VARIABLE and are read from the server's
+// environment; plain credential labels are rejected because anyone who can
+// inspect the container can read them.
+func ParseLabels(prefix, source, defaultName string, labels map[string]string) (*Service, error) {
+	fields := make(map[string]string)
+	for label, raw := range labels {
+		if key, ok := strings.CutPrefix(label, prefix); ok && key != "" {
+			fields[key] = raw
+		}
+	}
+	if fields["type"] == "" || fields["enable"] == "false" {
+		return nil, nil
+	}
+
+	service := &Service{
+		Source:    source,
+		Name:      defaultName,
+		Type:      fields["type"],
+		Dashboard: fields["dashboard"],
+		Config:    models.JSON{},
+	}
+	if name := fields["name"]; name != "" {
+		service.Name = name
+	}
+	service.Config["title"] = service.Name
+
+	for key, raw := range fields {
+		switch key {
+		case "type", "name", "dashboard", "enable":
+			continue
+		case "url":
+			service.Config["serverUrl"] = raw
+			continue
+		}
+
+		if field, ok := strings.CutSuffix(key, ".env"); ok {
+			credential, err := secret(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", field, err)
+			}
+			service.Config[field] = credential
+			continue
+		}
+		if models.IsSensitiveField(key) {
+			return nil, fmt.Errorf("%s must reference an environment variable with %s%s.env", key, prefix, key)
+		}
+		service.Config[key] = value(raw)
+	}
+
+	return service, nil
+}
+
+// secret reads a credential from the server's environment. Only variables
+// with DISCOVERY_SECRET_PREFIX can be referenced, so a label cannot pull
+// out unrelated server secrets.
+func secret(name string) (string, error) {
+	prefix := config.String("DISCOVERY_SECRET_PREFIX", "NEON_BRIDGE_")
+	if !strings.HasPrefix(name, prefix) {
+		return "", fmt.Errorf("environment variable %s does not start with %s", name, prefix)
+	}
+	value := os.Getenv(name)
+	if value == "" {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// value converts a label string into the JSON type the widget expects.
+func value(raw string) interface{} {
+	switch raw {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if n, err := strconv.ParseFloat(raw, 64); err == nil {
+		return n
+	}
+	return raw
+}
@@ -0,0 +1,77 @@
+package discovery
+
+import (
+	"path/filepath"
+	"testing"
+
+	"dashboard-server/database"
+	"dashboard-server/models"
+
+	"gorm.io/gorm"
+)
+
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestSyncKeepsUserDisabledWidgets(t *testing.T) {
+	db := openDB(t)
+
+	services := []Service{
+		{Source: "docker:sonarr", Name: "Sonarr", Type: "sonarr", Config: models.JSON{"serverUrl": "http://sonarr:8989"}},
+		{Source: "docker:radarr", Name: "Radarr", Type: "radarr", Config: models.JSON{"serverUrl": "http://radarr:7878"}},
+	}
+	sync := func(services ...Service) {
+		t.Helper()
+		if err := Sync(db, ProviderDocker, services); err != nil {
+			t.Fatalf("Sync: %v", err)
+		}
+	}
+	enabled := func(source string) bool {
+		t.Helper()
+		var widget models.Widget
+		if err := db.Where("source = ?", source).First(&widget).Error; err != nil {
+			t.Fatal(err)
+		}
+		return widget.IsEnabled
+	}
+
+	sync(services...)
+	if !enabled("docker:sonarr") || !enabled("docker:radarr") {
+		t.Fatal("discovered widgets are not enabled")
+	}
+
+	// The user turns Sonarr off; later syncs must leave it off.
+	if err := db.Model(&models.Widget{}).Where("source = ?", "docker:sonarr").Update("is_enabled", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	sync(services...)
+	if enabled("docker:sonarr") {
+		t.Error("sync re-enabled a widget the user disabled")
+	}
+
+	// Radarr's container goes away and comes back.
+	sync(services[0])
+	if enabled("docker:radarr") {
+		t.Error("widget of a vanished service is still enabled")
+	}
+	sync(services...)
+	if !enabled("docker:radarr") {
+		t.Error("widget was not re-enabled when its service returned")
+	}
+	if enabled("docker:sonarr") {
+		t.Error("sync re-enabled a widget the user disabled after its service returned")
+	}
+
+	// A user-disabled widget whose service disappears and returns stays off.
+	sync(services[1])
+	sync(services...)
+	if enabled("docker:sonarr") {
+		t.Error("sync re-enabled a user-disabled widget after its service returned")
+	}
+}
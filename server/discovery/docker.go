@@ -0,0 +1,119 @@
+package discovery
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"dashboard-server/services"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ProviderDocker = "docker"
+
+	// LabelPrefix starts every label read by discovery, e.g.
+	// neon-bridge.type=sonarr.
+	LabelPrefix = "neon-bridge."
+
+	dockerSettle     = 2 * time.Second
+	dockerRetryDelay = 10 * time.Second
+)
+
+// dockerEvents are the container events that can add or remove a service.
+var dockerEvents = map[string]bool{
+	"create":  true,
+	"start":   true,
+	"destroy": true,
+	"rename":  true,
+}
+
+// DockerServices reads services from container labels. Containers whose
+// labels are invalid are logged and skipped.
+func DockerServices(containers []services.DockerContainer) []Service {
+	var found []Service
+	for _, container := range containers {
+		name := container.Name()
+		service, err := ParseLabels(LabelPrefix, ProviderDocker+":"+name, name, container.Labels)
+		if err != nil {
+			slog.Warn("Ignoring container with invalid discovery labels", "container", name, "error", err)
+			continue
+		}
+		if service != nil {
+			found = append(found, *service)
+		}
+	}
+	return found
+}
+
+// SyncDocker syncs the widgets of every labelled container, running or not.
+// Widgets are only disabled once their container is removed.
+func SyncDocker(ctx context.Context, db *gorm.DB, client *services.DockerClient) error {
+	containers, err := client.ListContainers(ctx, true)
+	if err != nil {
+		return err
+	}
+	return Sync(db, ProviderDocker, DockerServices(containers))
+}
+
+// RunDocker syncs once, then again shortly after each container event and
+// every interval in case an event was missed.
+func RunDocker(ctx context.Context, db *gorm.DB, client *services.DockerClient, interval time.Duration) {
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	go watchDocker(ctx, client, notify)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := SyncDocker(ctx, db, client); err != nil {
+			slog.Error("Docker discovery failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-changed:
+			// Let bursts such as a compose up settle into one sync.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(dockerSettle):
+			}
+			select {
+			case <-changed:
+			default:
+			}
+		}
+	}
+}
+
+func watchDocker(ctx context.Context, client *services.DockerClient, notify func()) {
+	for {
+		err := client.Events(ctx, func(event services.DockerEvent) {
+			if dockerEvents[event.Action] {
+				notify()
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("Docker event stream disconnected, retrying", "error", err, "retry_in", dockerRetryDelay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(dockerRetryDelay):
+		}
+		// Events may have been missed while disconnected.
+		notify()
+	}
+}
@@ -0,0 +1,119 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"dashboard-server/services"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ProviderKubernetes = "kubernetes"
+
+	// AnnotationPrefix starts every annotation read by discovery, e.g.
+	// neon-bridge/type: sonarr.
+	AnnotationPrefix = "neon-bridge/"
+)
+
+// KubernetesServices reads services from Service and Ingress annotations.
+// Without a url annotation, ingresses link to their first host and services
+// to their cluster DNS name.
+func KubernetesServices(svcs []services.KubernetesService, ingresses []services.KubernetesIngress) []Service {
+	var found []Service
+	add := func(kind string, meta services.KubernetesMeta, defaultURL string) {
+		source := fmt.Sprintf("%s:%s/%s/%s", ProviderKubernetes, kind, meta.Namespace, meta.Name)
+		service, err := ParseLabels(AnnotationPrefix, source, meta.Name, meta.Annotations)
+		if err != nil {
+			slog.Warn("Ignoring resource with invalid discovery annotations", "kind", kind, "namespace", meta.Namespace, "name", meta.Name, "error", err)
+			return
+		}
+		if service == nil {
+			return
+		}
+		if _, ok := service.Config["serverUrl"]; !ok && defaultURL != "" {
+			service.Config["serverUrl"] = defaultURL
+		}
+		found = append(found, *service)
+	}
+
+	for i := range ingresses {
+		add("ingress", ingresses[i].Metadata, ingressURL(&ingresses[i]))
+	}
+	for i := range svcs {
+		add("service", svcs[i].Metadata, serviceURL(&svcs[i]))
+	}
+	return found
+}
+
+func ingressURL(ingress *services.KubernetesIngress) string {
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host == "" {
+			continue
+		}
+		for _, tls := range ingress.Spec.TLS {
+			if slices.Contains(tls.Hosts, rule.Host) {
+				return "https://" + rule.Host
+			}
+		}
+		return "http://" + rule.Host
+	}
+	return ""
+}
+
+// serviceURL prefers a port named http or https, then the first port.
+func serviceURL(service *services.KubernetesService) string {
+	ports := service.Spec.Ports
+	if len(ports) == 0 {
+		return ""
+	}
+	scheme, port := "http", ports[0].Port
+	for _, p := range ports {
+		if p.Name == "http" || p.Name == "https" {
+			scheme, port = p.Name, p.Port
+			break
+		}
+	}
+	return fmt.Sprintf("%s://%s.%s.svc:%d", scheme, service.Metadata.Name, service.Metadata.Namespace, port)
+}
+
+// SyncKubernetes syncs the widgets of every annotated Service and Ingress in
+// the cluster. Nothing is disabled if the cluster cannot be read.
+func SyncKubernetes(ctx context.Context, db *gorm.DB, kubeContext string) error {
+	client, err := services.NewKubernetesClient(kubeContext)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	svcs, err := client.Services(ctx)
+	if err != nil {
+		return err
+	}
+	ingresses, err := client.Ingresses(ctx)
+	if err != nil {
+		return err
+	}
+	return Sync(db, ProviderKubernetes, KubernetesServices(svcs, ingresses))
+}
+
+func RunKubernetes(ctx context.Context, db *gorm.DB, kubeContext string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := SyncKubernetes(ctx, db, kubeContext); err != nil {
+			slog.Error("Kubernetes discovery failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
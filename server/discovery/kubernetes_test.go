@@ -0,0 +1,176 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dashboard-server/models"
+	"dashboard-server/services"
+)
+
+func TestKubernetesServicesURLs(t *testing.T) {
+	annotated := func(name string, annotations map[string]string) services.KubernetesMeta {
+		meta := services.KubernetesMeta{Name: name, Namespace: "media", Annotations: map[string]string{"neon-bridge/type": "sonarr"}}
+		for key, value := range annotations {
+			meta.Annotations[key] = value
+		}
+		return meta
+	}
+	service := func(meta services.KubernetesMeta, ports ...string) services.KubernetesService {
+		var svc services.KubernetesService
+		svc.Metadata = meta
+		for i, name := range ports {
+			svc.Spec.Ports = append(svc.Spec.Ports, struct {
+				Name string `json:"name"`
+				Port int    `json:"port"`
+			}{name, 8000 + i})
+		}
+		return svc
+	}
+	ingress := func(meta services.KubernetesMeta, tlsHosts []string, hosts ...string) services.KubernetesIngress {
+		var ing services.KubernetesIngress
+		ing.Metadata = meta
+		if tlsHosts != nil {
+			ing.Spec.TLS = append(ing.Spec.TLS, struct {
+				Hosts []string `json:"hosts"`
+			}{tlsHosts})
+		}
+		for _, host := range hosts {
+			ing.Spec.Rules = append(ing.Spec.Rules, struct {
+				Host string `json:"host"`
+			}{host})
+		}
+		return ing
+	}
+
+	tests := []struct {
+		name      string
+		services  []services.KubernetesService
+		ingresses []services.KubernetesIngress
+		source    string
+		want      interface{}
+	}{
+		{
+			name:     "first port",
+			services: []services.KubernetesService{service(annotated("sonarr", nil), "metrics", "web")},
+			source:   "kubernetes:service/media/sonarr",
+			want:     "http://sonarr.media.svc:8000",
+		},
+		{
+			name:     "port named https",
+			services: []services.KubernetesService{service(annotated("sonarr", nil), "metrics", "https")},
+			source:   "kubernetes:service/media/sonarr",
+			want:     "https://sonarr.media.svc:8001",
+		},
+		{
+			name:     "port named http",
+			services: []services.KubernetesService{service(annotated("sonarr", nil), "metrics", "http", "https")},
+			source:   "kubernetes:service/media/sonarr",
+			want:     "http://sonarr.media.svc:8001",
+		},
+		{
+			name:     "no ports",
+			services: []services.KubernetesService{service(annotated("sonarr", nil))},
+			source:   "kubernetes:service/media/sonarr",
+			want:     nil,
+		},
+		{
+			name:     "url annotation wins",
+			services: []services.KubernetesService{service(annotated("sonarr", map[string]string{"neon-bridge/url": "https://tv.example.com"}), "http")},
+			source:   "kubernetes:service/media/sonarr",
+			want:     "https://tv.example.com",
+		},
+		{
+			name:      "ingress with tls",
+			ingresses: []services.KubernetesIngress{ingress(annotated("sonarr", nil), []string{"tv.example.com"}, "", "tv.example.com")},
+			source:    "kubernetes:ingress/media/sonarr",
+			want:      "https://tv.example.com",
+		},
+		{
+			name:      "ingress without tls for its host",
+			ingresses: []services.KubernetesIngress{ingress(annotated("sonarr", nil), []string{"other.example.com"}, "tv.lan")},
+			source:    "kubernetes:ingress/media/sonarr",
+			want:      "http://tv.lan",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := KubernetesServices(tt.services, tt.ingresses)
+			if len(found) != 1 || found[0].Source != tt.source {
+				t.Fatalf("services = %+v", found)
+			}
+			if got := found[0].Config["serverUrl"]; got != tt.want {
+				t.Errorf("serverUrl = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	unannotated := services.KubernetesService{Metadata: services.KubernetesMeta{Name: "kube-dns", Namespace: "kube-system"}}
+	if found := KubernetesServices([]services.KubernetesService{unannotated}, nil); len(found) != 0 {
+		t.Errorf("unannotated service discovered: %+v", found)
+	}
+}
+
+func TestSyncKubernetes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items := []interface{}{}
+		switch r.URL.Path {
+		case "/api/v1/services":
+			items = append(items, map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "radarr", "namespace": "media", "annotations": map[string]string{
+					"neon-bridge/type": "radarr", "neon-bridge/name": "Movies", "neon-bridge/dashboard": "Media",
+				}},
+				"spec": map[string]interface{}{"ports": []map[string]interface{}{{"name": "http", "port": 7878}}},
+			})
+		case "/apis/networking.k8s.io/v1/ingresses":
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	}))
+	defer server.Close()
+
+	kubeconfig := fmt.Sprintf(`current-context: test
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+users:
+- name: test
+  user:
+    token: test-token
+`, server.URL)
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", path)
+
+	db := openDB(t)
+	if err := SyncKubernetes(context.Background(), db, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	var widget models.Widget
+	if err := db.Preload("Dashboard").Where("source = ?", "kubernetes:service/media/radarr").First(&widget).Error; err != nil {
+		t.Fatal(err)
+	}
+	if widget.Name != "Movies" || widget.Type != "radarr" || !widget.IsEnabled || widget.Dashboard.Name != "Media" {
+		t.Errorf("widget = %+v", widget)
+	}
+	if got := widget.Config["serverUrl"]; got != "http://radarr.media.svc:7878" {
+		t.Errorf("serverUrl = %v", got)
+	}
+}
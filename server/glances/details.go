@@ -0,0 +1,445 @@
+package glances
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Details is everything a host reports, as structured fields. Sizes are in
+// bytes, rates in bytes per second and percentages in 0-100. Sections the
+// host does not report are left empty.
+type Details struct {
+	Host        HostInfo     `json:"host"`
+	CPU         CPUDetails   `json:"cpu"`
+	Memory      Usage        `json:"memory"`
+	Swap        Usage        `json:"swap"`
+	Load        Load         `json:"load"`
+	Processes   ProcessCount `json:"processes"`
+	Filesystems []Filesystem `json:"filesystems"`
+	Disks       []DiskIO     `json:"disks"`
+	Network     []NetworkIO  `json:"network"`
+	Sensors     []Sensor     `json:"sensors"`
+	Containers  []Container  `json:"containers"`
+	RAID        []RAIDArray  `json:"raid"`
+	SMART       []SMARTDisk  `json:"smart"`
+	TopCPU      []Process    `json:"top_cpu"`
+	TopMemory   []Process    `json:"top_memory"`
+}
+
+type HostInfo struct {
+	Hostname      string `json:"hostname"`
+	OS            string `json:"os"`
+	Platform      string `json:"platform"`
+	Version       string `json:"version"`
+	UptimeSeconds uint64 `json:"uptime_seconds"`
+}
+
+type CPUDetails struct {
+	Usage       float64   `json:"usage"`
+	Temperature float64   `json:"temperature"`
+	Cores       []float64 `json:"cores"`
+}
+
+type Usage struct {
+	Used       float64 `json:"used"`
+	Total      float64 `json:"total"`
+	Percentage float64 `json:"percentage"`
+}
+
+type Load struct {
+	Min1  float64 `json:"min1"`
+	Min5  float64 `json:"min5"`
+	Min15 float64 `json:"min15"`
+}
+
+type ProcessCount struct {
+	Total    int `json:"total"`
+	Running  int `json:"running"`
+	Sleeping int `json:"sleeping"`
+	Threads  int `json:"threads"`
+}
+
+type Filesystem struct {
+	Mount      string  `json:"mount"`
+	Device     string  `json:"device"`
+	Type       string  `json:"type"`
+	Used       float64 `json:"used"`
+	Free       float64 `json:"free"`
+	Total      float64 `json:"total"`
+	Percentage float64 `json:"percentage"`
+}
+
+type DiskIO struct {
+	Name       string  `json:"name"`
+	ReadRate   float64 `json:"read_rate"`
+	WriteRate  float64 `json:"write_rate"`
+	ReadBytes  float64 `json:"read_bytes"`
+	WriteBytes float64 `json:"write_bytes"`
+}
+
+type NetworkIO struct {
+	Interface string  `json:"interface"`
+	RxRate    float64 `json:"rx_rate"`
+	TxRate    float64 `json:"tx_rate"`
+	RxBytes   float64 `json:"rx_bytes"`
+	TxBytes   float64 `json:"tx_bytes"`
+}
+
+type Sensor struct {
+	Label string  `json:"label"`
+	Type  string  `json:"type"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+type Container struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Image       string  `json:"image"`
+	Status      string  `json:"status"`
+	CPUPercent  float64 `json:"cpu_percent"`
+	MemoryUsage float64 `json:"memory_usage"`
+	MemoryLimit float64 `json:"memory_limit"`
+}
+
+type RAIDArray struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Status     string   `json:"status"`
+	Used       int      `json:"used"`
+	Available  int      `json:"available"`
+	Components []string `json:"components"`
+}
+
+type SMARTDisk struct {
+	Device     string           `json:"device"`
+	Model      string           `json:"model"`
+	Attributes []SMARTAttribute `json:"attributes"`
+}
+
+type SMARTAttribute struct {
+	ID    string      `json:"id"`
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+	Raw   interface{} `json:"raw"`
+}
+
+type Process struct {
+	PID           int     `json:"pid"`
+	Name          string  `json:"name"`
+	Username      string  `json:"username"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryPercent float64 `json:"memory_percent"`
+	MemoryRSS     float64 `json:"memory_rss"`
+}
+
+// TopProcesses is how many processes each top list holds.
+const TopProcesses = 10
+
+func FetchDetails(ctx context.Context, config *Config) (*Details, error) {
+	data, _, err := Get(ctx, config, "all")
+	if err != nil {
+		return nil, err
+	}
+
+	all, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected Glances response")
+	}
+	return Detail(all), nil
+}
+
+// Detail converts a v3 or v4 "all" response. Where the versions name a field
+// differently both names are read; v3 I/O counters are turned into rates
+// using time_since_update.
+func Detail(data map[string]interface{}) *Details {
+	stats := Summarize(data)
+	details := &Details{
+		Filesystems: []Filesystem{},
+		Disks:       []DiskIO{},
+		Network:     []NetworkIO{},
+		Sensors:     []Sensor{},
+		Containers:  []Container{},
+		RAID:        []RAIDArray{},
+		SMART:       []SMARTDisk{},
+	}
+
+	system := object(data["system"])
+	details.Host = HostInfo{
+		Hostname: text(system["hostname"]),
+		OS:       text(system["os_name"]),
+		Platform: text(system["platform"]),
+		Version:  first(text(system["linux_distro"]), text(system["os_version"])),
+	}
+	details.Host.UptimeSeconds, _ = uptimeSeconds(data["uptime"])
+
+	details.CPU.Usage = stats.CPU.Usage
+	details.CPU.Temperature = stats.CPU.Temperature
+	details.CPU.Cores = []float64{}
+	for _, core := range list(data["percpu"]) {
+		details.CPU.Cores = append(details.CPU.Cores, number(object(core)["total"]))
+	}
+
+	details.Memory = usage(object(data["mem"]))
+	details.Swap = usage(object(data["memswap"]))
+
+	load := object(data["load"])
+	details.Load = Load{Min1: number(load["min1"]), Min5: number(load["min5"]), Min15: number(load["min15"])}
+
+	processes := object(data["processcount"])
+	details.Processes = ProcessCount{
+		Total:    int(number(processes["total"])),
+		Running:  int(number(processes["running"])),
+		Sleeping: int(number(processes["sleeping"])),
+		Threads:  int(number(processes["thread"])),
+	}
+
+	for _, item := range list(data["fs"]) {
+		fs := object(item)
+		details.Filesystems = append(details.Filesystems, Filesystem{
+			Mount:      text(fs["mnt_point"]),
+			Device:     text(fs["device_name"]),
+			Type:       text(fs["fs_type"]),
+			Used:       number(fs["used"]),
+			Free:       number(fs["free"]),
+			Total:      number(fs["size"]),
+			Percentage: number(fs["percent"]),
+		})
+	}
+
+	for _, item := range list(data["diskio"]) {
+		disk := object(item)
+		readBytes := number(disk["read_bytes"])
+		writeBytes := number(disk["write_bytes"])
+		details.Disks = append(details.Disks, DiskIO{
+			Name:       text(disk["disk_name"]),
+			ReadRate:   rate(disk, "read_bytes_rate_per_sec", readBytes),
+			WriteRate:  rate(disk, "write_bytes_rate_per_sec", writeBytes),
+			ReadBytes:  readBytes,
+			WriteBytes: writeBytes,
+		})
+	}
+
+	for _, item := range list(data["network"]) {
+		iface := object(item)
+		// v4 reports bytes_recv/bytes_sent, v3 rx/tx, both since the last update.
+		rx := first(iface["bytes_recv"], iface["rx"])
+		tx := first(iface["bytes_sent"], iface["tx"])
+		details.Network = append(details.Network, NetworkIO{
+			Interface: text(iface["interface_name"]),
+			RxRate:    rate(iface, "bytes_recv_rate_per_sec", number(rx)),
+			TxRate:    rate(iface, "bytes_sent_rate_per_sec", number(tx)),
+			RxBytes:   number(first(iface["bytes_recv_gauge"], iface["cumulative_rx"])),
+			TxBytes:   number(first(iface["bytes_sent_gauge"], iface["cumulative_tx"])),
+		})
+	}
+
+	for _, item := range list(data["sensors"]) {
+		sensor := object(item)
+		details.Sensors = append(details.Sensors, Sensor{
+			Label: text(sensor["label"]),
+			Type:  text(sensor["type"]),
+			Value: number(sensor["value"]),
+			Unit:  text(sensor["unit"]),
+		})
+	}
+
+	details.Containers = containers(data)
+	details.RAID = raidArrays(object(data["raid"]))
+	details.SMART = smartDisks(object(data["smart"]))
+	details.TopCPU, details.TopMemory = topProcesses(list(data["processlist"]))
+
+	return details
+}
+
+// Summary reduces details to the headline Stats.
+func (d *Details) Summary() *Stats {
+	stats := &Stats{}
+	stats.CPU.Usage = d.CPU.Usage
+	stats.CPU.Temperature = d.CPU.Temperature
+	stats.Memory.Used = d.Memory.Used / (1024 * 1024 * 1024)
+	stats.Memory.Total = d.Memory.Total / (1024 * 1024 * 1024)
+	stats.Memory.Percentage = d.Memory.Percentage
+	stats.Uptime.Days = int(d.Host.UptimeSeconds / (24 * 3600))
+	stats.Uptime.Display = FormatUptime(d.Host.UptimeSeconds)
+	stats.LoadAverage = d.Load.Min1
+	stats.Processes = d.Processes.Total
+	return stats
+}
+
+func object(value interface{}) map[string]interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
+
+func list(value interface{}) []interface{} {
+	if l, ok := value.([]interface{}); ok {
+		return l
+	}
+	return nil
+}
+
+func text(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, text(item))
+		}
+		return strings.Join(parts, ", ")
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// first returns the first value that is set.
+func first[T comparable](values ...T) T {
+	var zero T
+	for _, value := range values {
+		if value != zero {
+			return value
+		}
+	}
+	return zero
+}
+
+func usage(data map[string]interface{}) Usage {
+	return Usage{
+		Used:       number(data["used"]),
+		Total:      number(data["total"]),
+		Percentage: number(data["percent"]),
+	}
+}
+
+// rate prefers the v4 per-second field and otherwise divides the v3 counter
+// by the seconds it covers.
+func rate(data map[string]interface{}, field string, counter float64) float64 {
+	if value, ok := data[field]; ok {
+		return number(value)
+	}
+	if seconds := number(data["time_since_update"]); seconds > 0 {
+		return counter / seconds
+	}
+	return 0
+}
+
+// containers reads the v4 "containers" list or the v3 "docker" plugin, which
+// is either a list or {"containers": [...]}.
+func containers(data map[string]interface{}) []Container {
+	items := list(data["containers"])
+	if items == nil {
+		items = list(data["docker"])
+	}
+	if items == nil {
+		items = list(object(data["docker"])["containers"])
+	}
+
+	result := []Container{}
+	for _, item := range items {
+		container := object(item)
+		memory := object(container["memory"])
+		cpu := first(container["cpu_percent"], object(container["cpu"])["total"])
+		result = append(result, Container{
+			ID:          text(first(container["id"], container["Id"])),
+			Name:        text(container["name"]),
+			Image:       text(container["image"]),
+			Status:      text(first(container["status"], container["Status"])),
+			CPUPercent:  number(cpu),
+			MemoryUsage: number(first(container["memory_usage"], memory["usage"])),
+			MemoryLimit: number(first(container["memory_limit"], memory["limit"])),
+		})
+	}
+	return result
+}
+
+func raidArrays(data map[string]interface{}) []RAIDArray {
+	result := []RAIDArray{}
+	for _, name := range sortedKeys(data) {
+		array := object(data[name])
+		components := []string{}
+		for _, component := range sortedKeys(object(array["components"])) {
+			components = append(components, component)
+		}
+		result = append(result, RAIDArray{
+			Name:       name,
+			Type:       text(array["type"]),
+			Status:     text(array["status"]),
+			Used:       int(number(array["used"])),
+			Available:  int(number(array["available"])),
+			Components: components,
+		})
+	}
+	return result
+}
+
+// smartDisks reads the smart plugin: one object per device holding its model
+// under DeviceName and each attribute under its numeric ID.
+func smartDisks(data map[string]interface{}) []SMARTDisk {
+	result := []SMARTDisk{}
+	for _, device := range sortedKeys(data) {
+		disk := object(data[device])
+		smart := SMARTDisk{Device: device, Model: text(disk["DeviceName"]), Attributes: []SMARTAttribute{}}
+		for _, id := range sortedKeys(disk) {
+			attribute, ok := disk[id].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			smart.Attributes = append(smart.Attributes, SMARTAttribute{
+				ID:    id,
+				Name:  text(attribute["name"]),
+				Value: attribute["value"],
+				Raw:   attribute["raw"],
+			})
+		}
+		result = append(result, smart)
+	}
+	return result
+}
+
+func sortedKeys(data map[string]interface{}) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func topProcesses(items []interface{}) ([]Process, []Process) {
+	processes := make([]Process, 0, len(items))
+	for _, item := range items {
+		process := object(item)
+		rss := number(object(process["memory_info"])["rss"])
+		if info := list(process["memory_info"]); len(info) > 0 {
+			rss = number(info[0])
+		}
+		processes = append(processes, Process{
+			PID:           int(number(process["pid"])),
+			Name:          text(process["name"]),
+			Username:      text(process["username"]),
+			CPUPercent:    number(process["cpu_percent"]),
+			MemoryPercent: number(process["memory_percent"]),
+			MemoryRSS:     rss,
+		})
+	}
+
+	return TopBy(processes, func(p Process) float64 { return p.CPUPercent }),
+		TopBy(processes, func(p Process) float64 { return p.MemoryPercent })
+}
+
+// TopBy returns the TopProcesses processes with the highest key.
+func TopBy(processes []Process, key func(Process) float64) []Process {
+	sorted := append([]Process{}, processes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return key(sorted[i]) > key(sorted[j]) })
+	if len(sorted) > TopProcesses {
+		sorted = sorted[:TopProcesses]
+	}
+	return sorted
+}
@@ -0,0 +1,298 @@
+// Package glances is the client for Glances (https://nicolargo.github.io/glances/).
+// It works with both the v3 and v4 REST APIs and reduces either to Stats.
+package glances
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dashboard-server/services"
+)
+
+type Config struct {
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+type Stats struct {
+	CPU struct {
+		Usage       float64 `json:"usage"`
+		Temperature float64 `json:"temperature"`
+	} `json:"cpu"`
+	Memory struct {
+		Used       float64 `json:"used"`
+		Total      float64 `json:"total"`
+		Percentage float64 `json:"percentage"`
+	} `json:"memory"`
+	Uptime struct {
+		Days    int    `json:"days"`
+		Display string `json:"display"`
+	} `json:"uptime"`
+	LoadAverage float64 `json:"loadAverage"`
+	Processes   int     `json:"processes"`
+}
+
+// Versions are tried newest first. The version that answered is remembered
+// per URL so later requests go straight to it.
+var versions = []int{4, 3}
+
+var detected sync.Map
+
+var errNotFound = errors.New("not found")
+
+var client = services.NewHTTPClient(10*time.Second, false)
+
+// ParseConfig reads the JSON form stored on dashboards.
+func ParseConfig(raw string) (*Config, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("no Glances configuration found")
+	}
+
+	var config Config
+	if err := json.Unmarshal([]byte(raw), &config); err != nil {
+		return nil, fmt.Errorf("invalid Glances configuration: %w", err)
+	}
+	if config.URL == "" {
+		return nil, fmt.Errorf("Glances URL not configured")
+	}
+	return &config, nil
+}
+
+func baseURL(config *Config) string {
+	url := strings.TrimRight(config.URL, "/")
+	for _, version := range versions {
+		url = strings.TrimSuffix(url, fmt.Sprintf("/api/%d", version))
+	}
+	return url
+}
+
+func get(ctx context.Context, config *Config, version int, endpoint string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/%d/%s", baseURL(config), version, endpoint), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if config.Username != "" && config.Password != "" {
+		req.SetBasicAuth(config.Username, config.Password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("glances API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
+
+// Get fetches an API endpoint such as "all" or "fs", detecting the API
+// version on first use. It returns the decoded body and the version used.
+// A remembered version that stops answering is forgotten and the others are
+// tried, since the server may have been upgraded or replaced.
+func Get(ctx context.Context, config *Config, endpoint string) (interface{}, int, error) {
+	key := baseURL(config)
+	candidates := versions
+	cached, known := detected.Load(key)
+	if known {
+		candidates = []int{cached.(int)}
+		for _, version := range versions {
+			if version != cached.(int) {
+				candidates = append(candidates, version)
+			}
+		}
+	}
+
+	for _, version := range candidates {
+		body, err := get(ctx, config, version, endpoint)
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var data interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse response: %w", err)
+		}
+		detected.Store(key, version)
+		return data, version, nil
+	}
+
+	if known {
+		detected.Delete(key)
+		return nil, 0, fmt.Errorf("glances API endpoint %q: %w", endpoint, errNotFound)
+	}
+	return nil, 0, fmt.Errorf("no supported Glances API at %s: %w", key, errNotFound)
+}
+
+// Version reports which API version the server speaks.
+func Version(ctx context.Context, config *Config) (int, error) {
+	_, version, err := Get(ctx, config, "status")
+	if !errors.Is(err, errNotFound) {
+		return version, err
+	}
+	// Glances 3 before 3.2 has no status endpoint.
+	_, version, err = Get(ctx, config, "now")
+	return version, err
+}
+
+func Fetch(ctx context.Context, config *Config) (*Stats, error) {
+	data, _, err := Get(ctx, config, "all")
+	if err != nil {
+		return nil, err
+	}
+
+	all, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected Glances response")
+	}
+	return Summarize(all), nil
+}
+
+// Test checks the server is reachable, the credentials work and the API
+// version is supported.
+func Test(ctx context.Context, config *Config) error {
+	if _, err := Version(ctx, config); err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	return nil
+}
+
+// Summarize reduces a v3 or v4 "all" response to Stats.
+func Summarize(data map[string]interface{}) *Stats {
+	stats := &Stats{}
+
+	if cpu, ok := data["cpu"].(map[string]interface{}); ok {
+		stats.CPU.Usage = number(cpu["total"])
+	}
+
+	if mem, ok := data["mem"].(map[string]interface{}); ok {
+		stats.Memory.Used = number(mem["used"]) / (1024 * 1024 * 1024)
+		stats.Memory.Total = number(mem["total"]) / (1024 * 1024 * 1024)
+		stats.Memory.Percentage = number(mem["percent"])
+	}
+
+	if seconds, ok := uptimeSeconds(data["uptime"]); ok {
+		stats.Uptime.Days = int(seconds / (24 * 3600))
+		stats.Uptime.Display = FormatUptime(seconds)
+	}
+
+	if load, ok := data["load"].(map[string]interface{}); ok {
+		stats.LoadAverage = number(load["min1"])
+	}
+
+	if processes, ok := data["processcount"].(map[string]interface{}); ok {
+		stats.Processes = int(number(processes["total"]))
+	}
+
+	stats.CPU.Temperature = cpuTemperature(data["sensors"])
+
+	return stats
+}
+
+func number(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
+}
+
+var uptimePattern = regexp.MustCompile(`^(?:(\d+) days?, )?(\d+):(\d{2}):(\d{2})`)
+
+// uptimeSeconds accepts seconds or the "3 days, 4:05:06" string Glances
+// returns from the uptime plugin.
+func uptimeSeconds(value interface{}) (uint64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return uint64(v), true
+	case string:
+		match := uptimePattern.FindStringSubmatch(strings.TrimSpace(v))
+		if match == nil {
+			return 0, false
+		}
+		days, _ := strconv.ParseUint(match[1], 10, 64)
+		hours, _ := strconv.ParseUint(match[2], 10, 64)
+		minutes, _ := strconv.ParseUint(match[3], 10, 64)
+		seconds, _ := strconv.ParseUint(match[4], 10, 64)
+		return days*24*3600 + hours*3600 + minutes*60 + seconds, true
+	}
+	return 0, false
+}
+
+func FormatUptime(uptimeSeconds uint64) string {
+	days := uptimeSeconds / (24 * 3600)
+	hours := (uptimeSeconds % (24 * 3600)) / 3600
+	minutes := (uptimeSeconds % 3600) / 60
+
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh", days, hours)
+	} else if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	} else {
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// cpuSensors are labels known to report the package or first core, across
+// Intel, AMD and ARM boards.
+var cpuSensors = []string{"Package id 0", "Tctl", "Tdie", "CPU", "cpu_thermal", "Core 0", "acpitz 1"}
+
+func cpuTemperature(value interface{}) float64 {
+	sensors, ok := value.([]interface{})
+	if !ok {
+		return 0
+	}
+
+	readings := make(map[string]float64)
+	first := 0.0
+	for _, item := range sensors {
+		sensor, ok := item.(map[string]interface{})
+		if !ok || sensor["type"] != "temperature_core" {
+			continue
+		}
+		temperature := number(sensor["value"])
+		if unit, _ := sensor["unit"].(string); unit == "F" {
+			temperature = (temperature - 32) * 5 / 9
+		}
+		if temperature <= 0 || temperature >= 150 {
+			continue
+		}
+		label, _ := sensor["label"].(string)
+		readings[label] = temperature
+		if first == 0 {
+			first = temperature
+		}
+	}
+
+	for _, label := range cpuSensors {
+		if temperature, ok := readings[label]; ok {
+			return temperature
+		}
+	}
+	return first
+}
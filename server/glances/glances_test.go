@@ -0,0 +1,370 @@
+package glances
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func loadFixture(t *testing.T, name string) map[string]interface{} {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		fixture     string
+		cpu         float64
+		temperature float64
+		memoryUsed  float64
+		memoryTotal float64
+		memoryPct   float64
+		days        int
+		uptime      string
+		load        float64
+		processes   int
+	}{
+		{"v3_all.json", 23.4, 48, 6, 16, 37.5, 12, "12d 3h", 0.82, 245},
+		{"v4_all.json", 7.8, 52.5, 8, 32, 25, 1, "1d 0h", 0.31, 402},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			stats := Summarize(loadFixture(t, tt.fixture))
+
+			if stats.CPU.Usage != tt.cpu {
+				t.Errorf("CPU usage = %v, want %v", stats.CPU.Usage, tt.cpu)
+			}
+			if stats.CPU.Temperature != tt.temperature {
+				t.Errorf("CPU temperature = %v, want %v", stats.CPU.Temperature, tt.temperature)
+			}
+			if stats.Memory.Used != tt.memoryUsed || stats.Memory.Total != tt.memoryTotal || stats.Memory.Percentage != tt.memoryPct {
+				t.Errorf("memory = %+v, want %v/%v GiB %v%%", stats.Memory, tt.memoryUsed, tt.memoryTotal, tt.memoryPct)
+			}
+			if stats.Uptime.Days != tt.days || stats.Uptime.Display != tt.uptime {
+				t.Errorf("uptime = %+v, want %d days %q", stats.Uptime, tt.days, tt.uptime)
+			}
+			if stats.LoadAverage != tt.load {
+				t.Errorf("load = %v, want %v", stats.LoadAverage, tt.load)
+			}
+			if stats.Processes != tt.processes {
+				t.Errorf("processes = %d, want %d", stats.Processes, tt.processes)
+			}
+		})
+	}
+}
+
+func TestDetail(t *testing.T) {
+	tests := []struct {
+		fixture    string
+		hostname   string
+		version    string
+		uptime     uint64
+		cores      int
+		swapUsed   float64
+		disk       DiskIO
+		network    NetworkIO
+		containers []Container
+		raid       []RAIDArray
+		smartDisks int
+		topCPU     Process
+		topMemory  string
+	}{
+		{
+			fixture:  "v3_all.json",
+			hostname: "nas",
+			version:  "Ubuntu 22.04 Jammy Jellyfish",
+			uptime:   12*86400 + 3*3600 + 4*60 + 5,
+			cores:    4,
+			swapUsed: 1 << 29,
+			disk:     DiskIO{Name: "sda", ReadRate: 204800, WriteRate: 409600, ReadBytes: 409600, WriteBytes: 819200},
+			network:  NetworkIO{Interface: "eth0", RxRate: 10000, TxRate: 2000, RxBytes: 98765432, TxBytes: 1234567},
+			containers: []Container{
+				{ID: "4f1c2a9b", Name: "plex", Image: "plexinc/pms-docker:latest", Status: "running", CPUPercent: 4.2, MemoryUsage: 734003200, MemoryLimit: 16 << 30},
+			},
+			raid:       []RAIDArray{{Name: "md0", Type: "raid1", Status: "active", Used: 2, Available: 2, Components: []string{"sdb1", "sdc1"}}},
+			smartDisks: 1,
+			topCPU:     Process{PID: 1201, Name: "Plex Media Server", Username: "plex", CPUPercent: 12.5, MemoryPercent: 4.6, MemoryRSS: 734003200},
+			topMemory:  "Plex Media Server",
+		},
+		{
+			fixture:  "v4_all.json",
+			hostname: "pve-node1",
+			version:  "Debian GNU/Linux 12",
+			uptime:   86400 + 30*60 + 15,
+			cores:    8,
+			swapUsed: 0,
+			disk:     DiskIO{Name: "nvme0n1", ReadRate: 8192, WriteRate: 1048576, ReadBytes: 16384, WriteBytes: 2097152},
+			network:  NetworkIO{Interface: "vmbr0", RxRate: 15000, TxRate: 5000, RxBytes: 5000000000, TxBytes: 700000000},
+			containers: []Container{
+				{ID: "9e8d7c6b5a", Name: "homeassistant", Image: "ghcr.io/home-assistant/home-assistant:stable", Status: "running", CPUPercent: 2.5, MemoryUsage: 512000000, MemoryLimit: 4000000000},
+				{ID: "1a2b3c", Name: "unifi", Image: "lscr.io/linuxserver/unifi-network-application", Status: "exited"},
+			},
+			raid:       []RAIDArray{},
+			smartDisks: 0,
+			topCPU:     Process{PID: 3012, Name: "kvm", Username: "root", CPUPercent: 6.3, MemoryPercent: 12.5, MemoryRSS: 4294967296},
+			topMemory:  "kvm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			details := Detail(loadFixture(t, tt.fixture))
+
+			if details.Host.Hostname != tt.hostname || details.Host.Version != tt.version || details.Host.UptimeSeconds != tt.uptime {
+				t.Errorf("host = %+v, want %s %q up %ds", details.Host, tt.hostname, tt.version, tt.uptime)
+			}
+			if len(details.CPU.Cores) != tt.cores {
+				t.Errorf("got %d cores, want %d", len(details.CPU.Cores), tt.cores)
+			}
+			if details.Swap.Used != tt.swapUsed {
+				t.Errorf("swap used = %v, want %v", details.Swap.Used, tt.swapUsed)
+			}
+			if len(details.Disks) != 1 || details.Disks[0] != tt.disk {
+				t.Errorf("disks = %+v, want [%+v]", details.Disks, tt.disk)
+			}
+			if got := findInterface(details.Network, tt.network.Interface); got != tt.network {
+				t.Errorf("network = %+v, want %+v", got, tt.network)
+			}
+			if fmt.Sprint(details.Containers) != fmt.Sprint(tt.containers) {
+				t.Errorf("containers = %+v, want %+v", details.Containers, tt.containers)
+			}
+			if fmt.Sprint(details.RAID) != fmt.Sprint(tt.raid) {
+				t.Errorf("raid = %+v, want %+v", details.RAID, tt.raid)
+			}
+			if len(details.SMART) != tt.smartDisks {
+				t.Errorf("got %d SMART disks, want %d", len(details.SMART), tt.smartDisks)
+			}
+			if len(details.TopCPU) == 0 || details.TopCPU[0] != tt.topCPU {
+				t.Errorf("top CPU = %+v, want %+v first", details.TopCPU, tt.topCPU)
+			}
+			if len(details.TopMemory) == 0 || details.TopMemory[0].Name != tt.topMemory {
+				t.Errorf("top memory = %+v, want %s first", details.TopMemory, tt.topMemory)
+			}
+
+			summary := details.Summary()
+			if *summary != *Summarize(loadFixture(t, tt.fixture)) {
+				t.Errorf("Summary() = %+v, differs from Summarize", summary)
+			}
+		})
+	}
+}
+
+func findInterface(network []NetworkIO, name string) NetworkIO {
+	for _, iface := range network {
+		if iface.Interface == name {
+			return iface
+		}
+	}
+	return NetworkIO{}
+}
+
+func TestSMARTAttributes(t *testing.T) {
+	details := Detail(loadFixture(t, "v3_all.json"))
+	disk := details.SMART[0]
+	if disk.Device != "sdb" || disk.Model != "WDC WD40EFRX-68N32N0" {
+		t.Fatalf("disk = %s %q", disk.Device, disk.Model)
+	}
+	if len(disk.Attributes) != 2 || disk.Attributes[0].Name != "Temperature_Celsius" || disk.Attributes[1].Name != "Reallocated_Sector_Ct" {
+		t.Errorf("attributes = %+v", disk.Attributes)
+	}
+}
+
+func TestUptimeSeconds(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  uint64
+		ok    bool
+	}{
+		{float64(3600), 3600, true},
+		{"0:00:59", 59, true},
+		{"1:02:03", 3723, true},
+		{"1 day, 0:30:15", 88215, true},
+		{"12 days, 3:04:05", 1047845, true},
+		{" 2 days, 23:59:59.123456 ", 259199, true},
+		{"up 3 hours", 0, false},
+		{"", 0, false},
+		{nil, 0, false},
+		{true, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := uptimeSeconds(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("uptimeSeconds(%#v) = %d, %v; want %d, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFormatUptime(t *testing.T) {
+	tests := map[uint64]string{
+		59:      "0m",
+		3599:    "59m",
+		3723:    "1h 2m",
+		88215:   "1d 0h",
+		1047845: "12d 3h",
+	}
+	for seconds, want := range tests {
+		if got := FormatUptime(seconds); got != want {
+			t.Errorf("FormatUptime(%d) = %q, want %q", seconds, got, want)
+		}
+	}
+}
+
+func TestCPUTemperature(t *testing.T) {
+	sensor := func(label string, value interface{}, unit, kind string) interface{} {
+		return map[string]interface{}{"label": label, "value": value, "unit": unit, "type": kind}
+	}
+
+	tests := []struct {
+		name    string
+		sensors interface{}
+		want    float64
+	}{
+		{"no sensors", nil, 0},
+		{"not a list", map[string]interface{}{}, 0},
+		{"intel package preferred", []interface{}{
+			sensor("acpitz 1", 27.8, "C", "temperature_core"),
+			sensor("Core 0", 46.0, "C", "temperature_core"),
+			sensor("Package id 0", 48.0, "C", "temperature_core"),
+		}, 48},
+		{"amd tctl", []interface{}{
+			sensor("Composite", 41.0, "C", "temperature_core"),
+			sensor("Tctl", 52.5, "C", "temperature_core"),
+		}, 52.5},
+		{"raspberry pi", []interface{}{sensor("cpu_thermal", 55.0, "C", "temperature_core")}, 55},
+		{"unknown labels use the first reading", []interface{}{
+			sensor("nvme0 Sensor 1", 0.0, "C", "temperature_core"),
+			sensor("Composite", 38.0, "C", "temperature_core"),
+			sensor("pch", 44.0, "C", "temperature_core"),
+		}, 38},
+		{"fahrenheit", []interface{}{sensor("CPU", 122.0, "F", "temperature_core")}, 50},
+		{"string value", []interface{}{sensor("Core 0", "61.5", "C", "temperature_core")}, 61.5},
+		{"implausible readings ignored", []interface{}{
+			sensor("Package id 0", 255.0, "C", "temperature_core"),
+			sensor("Core 0", 47.0, "C", "temperature_core"),
+		}, 47},
+		{"other sensor types ignored", []interface{}{
+			sensor("fan1", 1180.0, "R", "fan_speed"),
+			sensor("BAT0", 98.0, "%", "battery"),
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cpuTemperature(tt.sensors); got != tt.want {
+				t.Errorf("cpuTemperature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeGlances serves the fixture of whichever API version is current, and
+// 404 for the other, like a real server.
+func fakeGlances(t *testing.T, version *atomic.Int32, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	fixtures := map[int32][]byte{}
+	for _, v := range []int32{3, 4} {
+		raw, err := os.ReadFile(filepath.Join("testdata", fmt.Sprintf("v%d_all.json", v)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fixtures[v] = raw
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		current := version.Load()
+		prefix := fmt.Sprintf("/api/%d/", current)
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			http.NotFound(w, r)
+			return
+		}
+		switch strings.TrimPrefix(r.URL.Path, prefix) {
+		case "all":
+			w.Write(fixtures[current])
+		case "status":
+			fmt.Fprintf(w, `{"version": "%d.0.0"}`, current)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVersionDetection(t *testing.T) {
+	var version, requests atomic.Int32
+	version.Store(4)
+	server := fakeGlances(t, &version, &requests)
+	config := &Config{URL: server.URL + "/api/4/"}
+	ctx := context.Background()
+
+	stats, err := Fetch(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Processes != 402 {
+		t.Errorf("processes = %d, want the v4 fixture's 402", stats.Processes)
+	}
+
+	requests.Store(0)
+	if _, err := Fetch(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("cached version took %d requests, want 1", requests.Load())
+	}
+
+	// Downgrading the server must not leave the cache pointing at v4.
+	version.Store(3)
+	stats, err = Fetch(ctx, config)
+	if err != nil {
+		t.Fatalf("after the version changed: %v", err)
+	}
+	if stats.Processes != 245 {
+		t.Errorf("processes = %d, want the v3 fixture's 245", stats.Processes)
+	}
+	if cached, _ := detected.Load(baseURL(config)); cached != 3 {
+		t.Errorf("cached version = %v, want 3", cached)
+	}
+
+	if got, err := Version(ctx, config); err != nil || got != 3 {
+		t.Errorf("Version() = %d, %v; want 3", got, err)
+	}
+
+	_, _, err = Get(ctx, config, "missing")
+	if err == nil || !strings.Contains(err.Error(), `endpoint "missing"`) {
+		t.Errorf("missing endpoint: err = %v", err)
+	}
+	if _, ok := detected.Load(baseURL(config)); ok {
+		t.Error("cache kept a version that answered nothing")
+	}
+	if _, err := Fetch(ctx, config); err != nil {
+		t.Errorf("detection after a miss: %v", err)
+	}
+}
+
+func TestNoSupportedVersion(t *testing.T) {
+	var version, requests atomic.Int32
+	version.Store(2)
+	server := fakeGlances(t, &version, &requests)
+
+	_, err := Fetch(context.Background(), &Config{URL: server.URL})
+	if err == nil || !strings.Contains(err.Error(), "no supported Glances API") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package glances
+
+import (
+	"fmt"
+
+	"dashboard-server/models"
+
+	"gorm.io/gorm"
+)
+
+// HostConfig returns the connection settings of a monitored host.
+func HostConfig(host *models.MonitoredHost) *Config {
+	return &Config{
+		URL:      host.URL,
+		Username: host.Username,
+		Password: host.Password,
+	}
+}
+
+// ImportHosts turns each dashboard's Glances configuration into a
+// monitored host, once, so hosts set up before hosts existed keep working.
+func ImportHosts(db *gorm.DB) error {
+	var dashboards []models.Dashboard
+	if err := db.Where("glances_config IS NOT NULL AND glances_config != ''").Find(&dashboards).Error; err != nil {
+		return err
+	}
+
+	for _, dashboard := range dashboards {
+		config, err := ParseConfig(dashboard.GlancesConfig)
+		if err != nil {
+			continue
+		}
+
+		var count int64
+		if err := db.Model(&models.MonitoredHost{}).Where("url = ?", config.URL).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		name, err := uniqueHostName(db, dashboard.Name)
+		if err != nil {
+			return err
+		}
+		host := models.MonitoredHost{
+			Name:     name,
+			Type:     models.HostTypeGlances,
+			URL:      config.URL,
+			Username: config.Username,
+			Password: config.Password,
+			Enabled:  true,
+		}
+		if err := db.Create(&host).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// uniqueHostName returns name, or name with the first free " (n)" suffix
+// when another host already uses it, since several dashboards may share a
+// name.
+func uniqueHostName(db *gorm.DB, name string) (string, error) {
+	candidate := name
+	for n := 2; ; n++ {
+		var count int64
+		if err := db.Model(&models.MonitoredHost{}).Where("name = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s (%d)", name, n)
+	}
+}
+
+// DashboardConfig returns the Glances configuration stored on a dashboard.
+func DashboardConfig(db *gorm.DB, dashboardID uint) (*Config, error) {
+	var dashboard models.Dashboard
+	if err := db.First(&dashboard, dashboardID).Error; err != nil {
+		return nil, fmt.Errorf("no dashboard found: %w", err)
+	}
+	return ParseConfig(dashboard.GlancesConfig)
+}
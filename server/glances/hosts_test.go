@@ -0,0 +1,56 @@
+package glances_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"dashboard-server/database"
+	"dashboard-server/glances"
+	"dashboard-server/models"
+)
+
+func TestImportHostsDuplicateNames(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dashboard := range []models.Dashboard{
+		{Name: "Home", GlancesConfig: `{"url":"http://nas:61208"}`},
+		{Name: "Home", GlancesConfig: `{"url":"http://pi:61208"}`},
+		{Name: "Home", GlancesConfig: `{"url":"http://router:61208"}`},
+		{Name: "Lab", GlancesConfig: `{"url":"http://lab:61208"}`},
+		{Name: "Broken", GlancesConfig: `{"url":`},
+	} {
+		if err := db.Create(&dashboard).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := glances.ImportHosts(db); err != nil {
+		t.Fatalf("ImportHosts: %v", err)
+	}
+	// A second run must not import anything again.
+	if err := glances.ImportHosts(db); err != nil {
+		t.Fatalf("second ImportHosts: %v", err)
+	}
+
+	var hosts []models.MonitoredHost
+	if err := db.Order("id").Find(&hosts).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"http://nas:61208":    "Home",
+		"http://pi:61208":     "Home (2)",
+		"http://router:61208": "Home (3)",
+		"http://lab:61208":    "Lab",
+	}
+	if len(hosts) != len(want) {
+		t.Fatalf("imported %d hosts, want %d: %+v", len(hosts), len(want), hosts)
+	}
+	for _, host := range hosts {
+		if want[host.URL] != host.Name {
+			t.Errorf("host %s named %q, want %q", host.URL, host.Name, want[host.URL])
+		}
+	}
+}
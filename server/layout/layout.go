@@ -0,0 +1,319 @@
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"dashboard-server/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultWidth  = 4
+	defaultHeight = 2
+
+	// MaxColumns and MaxRows bound the grid so a stored rectangle can
+	// neither overflow nor make placement scan an enormous empty area.
+	MaxColumns = 48
+	MaxRows    = 10000
+)
+
+// Conflict describes why a widget's placement was rejected.
+type Conflict struct {
+	Breakpoint string `json:"breakpoint"`
+	WidgetID   uint   `json:"widget_id"`
+	OtherID    uint   `json:"other_id,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+func (c Conflict) String() string {
+	if c.OtherID != 0 {
+		return fmt.Sprintf("%s: widget %d %s widget %d", c.Breakpoint, c.WidgetID, c.Reason, c.OtherID)
+	}
+	return fmt.Sprintf("%s: widget %d %s", c.Breakpoint, c.WidgetID, c.Reason)
+}
+
+// SameSection reports whether two section IDs, either of which may be nil
+// for the top-level grid, are the same section.
+func SameSection(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// Validate checks every widget against the grid bounds and against the
+// other widgets of its section. Widgets with no rectangle for a breakpoint
+// are skipped; Place fills those in.
+func Validate(columns models.LayoutColumns, widgets []models.Widget) []Conflict {
+	var conflicts []Conflict
+	for _, breakpoint := range models.Breakpoints {
+		cols := columns.For(breakpoint)
+		for i := range widgets {
+			rect, ok := widgets[i].Layout[breakpoint]
+			if !ok {
+				continue
+			}
+			if reason := checkBounds(rect, cols); reason != "" {
+				conflicts = append(conflicts, Conflict{Breakpoint: breakpoint, WidgetID: widgets[i].ID, Reason: reason})
+				continue
+			}
+
+			for j := i + 1; j < len(widgets); j++ {
+				other, ok := widgets[j].Layout[breakpoint]
+				if !ok || !SameSection(widgets[i].SectionID, widgets[j].SectionID) {
+					continue
+				}
+				if rect.Overlaps(other) {
+					conflicts = append(conflicts, Conflict{
+						Breakpoint: breakpoint,
+						WidgetID:   widgets[i].ID,
+						OtherID:    widgets[j].ID,
+						Reason:     "overlaps",
+					})
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+func checkBounds(rect models.GridRect, cols int) string {
+	switch {
+	case rect.W < 1 || rect.H < 1:
+		return "must be at least 1x1"
+	case rect.X < 0 || rect.Y < 0:
+		return "has a negative position"
+	case rect.W > cols || rect.X > cols-rect.W:
+		return fmt.Sprintf("does not fit in %d columns", cols)
+	case rect.H > MaxRows || rect.Y > MaxRows-rect.H:
+		return fmt.Sprintf("extends past row %d", MaxRows)
+	}
+	return ""
+}
+
+// Place gives w a valid rectangle on every breakpoint where it has none, or
+// where its current one is out of bounds or overlaps a sibling. It picks the
+// first free slot scanning top to bottom, left to right.
+func Place(columns models.LayoutColumns, siblings []models.Widget, w *models.Widget) {
+	if w.Layout == nil {
+		w.Layout = models.WidgetLayout{}
+	}
+
+	for _, breakpoint := range models.Breakpoints {
+		cols := columns.For(breakpoint)
+
+		var occupied []models.GridRect
+		for i := range siblings {
+			if siblings[i].ID == w.ID || !SameSection(siblings[i].SectionID, w.SectionID) {
+				continue
+			}
+			if rect, ok := siblings[i].Layout[breakpoint]; ok {
+				occupied = append(occupied, rect)
+			}
+		}
+
+		rect, ok := w.Layout[breakpoint]
+		if ok && checkBounds(rect, cols) == "" && free(rect, occupied) {
+			continue
+		}
+
+		size := models.GridRect{W: defaultWidth, H: defaultHeight}
+		if ok && rect.W >= 1 && rect.H >= 1 {
+			size.W, size.H = rect.W, rect.H
+		}
+		if size.W > cols {
+			size.W = cols
+		}
+		if size.H > MaxRows {
+			size.H = MaxRows
+		}
+		w.Layout[breakpoint] = firstFit(size, cols, occupied)
+	}
+}
+
+func free(rect models.GridRect, occupied []models.GridRect) bool {
+	for _, other := range occupied {
+		if rect.Overlaps(other) {
+			return false
+		}
+	}
+	return true
+}
+
+// firstFit only tries the top row and the rows just below occupied
+// rectangles: a free slot anywhere else could move up until it reaches one
+// of those, so the first fit is always on one of them.
+func firstFit(size models.GridRect, cols int, occupied []models.GridRect) models.GridRect {
+	rows := []int{0}
+	bottom := 0
+	for _, rect := range occupied {
+		rows = append(rows, rect.Y+rect.H)
+		bottom = max(bottom, rect.Y+rect.H)
+	}
+	slices.Sort(rows)
+
+	for _, y := range slices.Compact(rows) {
+		for x := 0; x+size.W <= cols; x++ {
+			candidate := models.GridRect{X: x, Y: y, W: size.W, H: size.H}
+			if free(candidate, occupied) {
+				return candidate
+			}
+		}
+	}
+	return models.GridRect{X: 0, Y: bottom, W: size.W, H: size.H}
+}
+
+var (
+	ErrUnknownSection    = errors.New("section does not belong to the widget's dashboard")
+	ErrUnknownBreakpoint = errors.New("unknown layout breakpoint")
+	ErrInvalidColumns    = errors.New("invalid column count")
+)
+
+// CheckColumns rejects unknown breakpoints and column counts outside
+// 1..MaxColumns.
+func CheckColumns(columns models.LayoutColumns) error {
+	for breakpoint, count := range columns {
+		if !slices.Contains(models.Breakpoints, breakpoint) {
+			return fmt.Errorf("%w %q", ErrUnknownBreakpoint, breakpoint)
+		}
+		if count < 1 || count > MaxColumns {
+			return fmt.Errorf("%w for %q: must be between 1 and %d", ErrInvalidColumns, breakpoint, MaxColumns)
+		}
+	}
+	return nil
+}
+
+// CheckBreakpoints rejects rectangles for breakpoints other than
+// models.Breakpoints, which would otherwise be stored and never used.
+func CheckBreakpoints(l models.WidgetLayout) error {
+	for breakpoint := range l {
+		if !slices.Contains(models.Breakpoints, breakpoint) {
+			return fmt.Errorf("%w %q", ErrUnknownBreakpoint, breakpoint)
+		}
+	}
+	return nil
+}
+
+// Fit validates a placement chosen by the user: the section must be on the
+// widget's dashboard, and explicit rectangles must fit and not overlap.
+// Breakpoints without a rectangle are then filled in with Place.
+func Fit(tx *gorm.DB, w *models.Widget) ([]Conflict, error) {
+	if err := CheckBreakpoints(w.Layout); err != nil {
+		return nil, err
+	}
+
+	var dashboard models.Dashboard
+	if err := tx.First(&dashboard, w.DashboardID).Error; err != nil {
+		return nil, err
+	}
+
+	if w.SectionID != nil {
+		var count int64
+		if err := tx.Model(&models.Section{}).Where("id = ? AND dashboard_id = ?", *w.SectionID, w.DashboardID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, ErrUnknownSection
+		}
+	}
+
+	var siblings []models.Widget
+	if err := tx.Where("dashboard_id = ? AND id <> ?", w.DashboardID, w.ID).Find(&siblings).Error; err != nil {
+		return nil, err
+	}
+
+	var conflicts []Conflict
+	for _, conflict := range Validate(dashboard.Columns, append(siblings, *w)) {
+		if conflict.WidgetID == w.ID || conflict.OtherID == w.ID {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	if len(conflicts) > 0 {
+		return conflicts, nil
+	}
+
+	Place(dashboard.Columns, siblings, w)
+	return nil, nil
+}
+
+// PlaceInDashboard loads the dashboard's columns and other widgets and
+// calls Place for w. A section that is not on the widget's dashboard is
+// dropped first.
+func PlaceInDashboard(tx *gorm.DB, w *models.Widget) error {
+	var dashboard models.Dashboard
+	if err := tx.First(&dashboard, w.DashboardID).Error; err != nil {
+		return err
+	}
+
+	if w.SectionID != nil {
+		var count int64
+		if err := tx.Model(&models.Section{}).Where("id = ? AND dashboard_id = ?", *w.SectionID, w.DashboardID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			w.SectionID = nil
+		}
+	}
+
+	var siblings []models.Widget
+	if err := tx.Where("dashboard_id = ? AND id <> ?", w.DashboardID, w.ID).Find(&siblings).Error; err != nil {
+		return err
+	}
+
+	Place(dashboard.Columns, siblings, w)
+	return nil
+}
+
+// Backfill places widgets stored before layouts existed, in their old
+// Position order, so every widget has a stored rectangle.
+func Backfill(db *gorm.DB) error {
+	var dashboardIDs []uint
+	if err := db.Model(&models.Widget{}).Where("layout IS NULL OR layout = ''").
+		Distinct().Pluck("dashboard_id", &dashboardIDs).Error; err != nil {
+		return err
+	}
+
+	for _, dashboardID := range dashboardIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var dashboard models.Dashboard
+			if err := tx.Unscoped().First(&dashboard, dashboardID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+
+			var widgets []models.Widget
+			if err := tx.Unscoped().Where("dashboard_id = ?", dashboardID).Find(&widgets).Error; err != nil {
+				return err
+			}
+			sort.SliceStable(widgets, func(i, j int) bool { return widgets[i].Position < widgets[j].Position })
+
+			var placed []models.Widget
+			for i := range widgets {
+				if len(widgets[i].Layout) > 0 {
+					placed = append(placed, widgets[i])
+				}
+			}
+			for i := range widgets {
+				if len(widgets[i].Layout) > 0 {
+					continue
+				}
+				Place(dashboard.Columns, placed, &widgets[i])
+				if err := tx.Unscoped().Model(&widgets[i]).UpdateColumn("layout", widgets[i].Layout).Error; err != nil {
+					return err
+				}
+				placed = append(placed, widgets[i])
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package layout
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"dashboard-server/models"
+)
+
+func TestCheckBounds(t *testing.T) {
+	tests := []struct {
+		name string
+		rect models.GridRect
+		ok   bool
+	}{
+		{"fits", models.GridRect{X: 8, Y: 10, W: 4, H: 2}, true},
+		{"bottom row", models.GridRect{X: 0, Y: MaxRows - 2, W: 4, H: 2}, true},
+		{"empty", models.GridRect{W: 0, H: 2}, false},
+		{"negative", models.GridRect{X: -1, W: 4, H: 2}, false},
+		{"too wide", models.GridRect{X: 9, W: 4, H: 2}, false},
+		{"x overflows", models.GridRect{X: math.MaxInt, W: 1, H: 1}, false},
+		{"w overflows", models.GridRect{X: 1, W: math.MaxInt, H: 1}, false},
+		{"too tall", models.GridRect{W: 4, H: MaxRows + 1}, false},
+		{"past the last row", models.GridRect{Y: MaxRows - 1, W: 4, H: 2}, false},
+		{"y overflows", models.GridRect{Y: math.MaxInt, W: 1, H: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := checkBounds(tt.rect, 12)
+			if (reason == "") != tt.ok {
+				t.Errorf("checkBounds(%+v) = %q, want ok=%v", tt.rect, reason, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCheckColumns(t *testing.T) {
+	if err := CheckColumns(models.LayoutColumns{models.BreakpointDesktop: 24}); err != nil {
+		t.Errorf("24 columns rejected: %v", err)
+	}
+	for _, columns := range []models.LayoutColumns{
+		{models.BreakpointDesktop: 0},
+		{models.BreakpointDesktop: MaxColumns + 1},
+		{"widescreen": 12},
+	} {
+		if err := CheckColumns(columns); err == nil {
+			t.Errorf("CheckColumns(%v) accepted", columns)
+		}
+	}
+}
+
+// bruteForceFit is the row-by-row scan firstFit replaces.
+func bruteForceFit(size models.GridRect, cols int, occupied []models.GridRect) models.GridRect {
+	for y := 0; ; y++ {
+		for x := 0; x+size.W <= cols; x++ {
+			candidate := models.GridRect{X: x, Y: y, W: size.W, H: size.H}
+			if free(candidate, occupied) {
+				return candidate
+			}
+		}
+	}
+}
+
+func TestFirstFitMatchesRowScan(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		var occupied []models.GridRect
+		for n := random.Intn(12); n > 0; n-- {
+			candidate := models.GridRect{W: 1 + random.Intn(6), H: 1 + random.Intn(4)}
+			candidate.X = random.Intn(12 - candidate.W + 1)
+			candidate.Y = random.Intn(10)
+			if free(candidate, occupied) {
+				occupied = append(occupied, candidate)
+			}
+		}
+		size := models.GridRect{W: 1 + random.Intn(8), H: 1 + random.Intn(3)}
+
+		if got, want := firstFit(size, 12, occupied), bruteForceFit(size, 12, occupied); got != want {
+			t.Fatalf("firstFit(%+v, %+v) = %+v, want %+v", size, occupied, got, want)
+		}
+	}
+}
+
+func TestPlaceBesideTallWidget(t *testing.T) {
+	tall := models.Widget{ID: 1, Layout: models.WidgetLayout{}}
+	for _, breakpoint := range models.Breakpoints {
+		tall.Layout[breakpoint] = models.GridRect{X: 0, Y: 0, W: 2, H: MaxRows / 2}
+	}
+	w := models.Widget{ID: 2}
+
+	start := time.Now()
+	Place(models.DefaultColumns, []models.Widget{tall}, &w)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Place took %v", elapsed)
+	}
+	if got := w.Layout[models.BreakpointDesktop]; got != (models.GridRect{X: 2, Y: 0, W: 4, H: 2}) {
+		t.Errorf("desktop = %+v", got)
+	}
+	// Four columns leave no room beside it on mobile.
+	if got := w.Layout[models.BreakpointMobile]; got != (models.GridRect{X: 0, Y: MaxRows / 2, W: 4, H: 2}) {
+		t.Errorf("mobile = %+v", got)
+	}
+}
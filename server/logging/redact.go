@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+
+	"dashboard-server/models"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	queryParamPattern = regexp.MustCompile(`([?&])([^=&\s#"']+)=([^&\s#"']*)`)
+	userInfoPattern   = regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+@`)
+	shareTokenPattern = regexp.MustCompile(`(/api/v1/share/)[^/\s?#"']+`)
+)
+
+// Redact masks credentials embedded in free text: sensitive query string
+// parameters such as ?apikey=, passwords in URL user info and share link
+// tokens, which are part of the path.
+func Redact(text string) string {
+	text = userInfoPattern.ReplaceAllString(text, "${1}"+redacted+"@")
+	text = shareTokenPattern.ReplaceAllString(text, "${1}"+redacted)
+	return queryParamPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := queryParamPattern.FindStringSubmatch(match)
+		if !models.IsSensitiveField(parts[2]) {
+			return match
+		}
+		return parts[1] + parts[2] + "=" + redacted
+	})
+}
+
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key != "request_id" && models.IsSensitiveField(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, Redact(err.Error()))
+		}
+	}
+	return attr
+}
@@ -1,29 +1,173 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"dashboard-server/certs"
+	"dashboard-server/config"
+	"dashboard-server/controllers"
 	"dashboard-server/database"
+	"dashboard-server/discovery"
+	"dashboard-server/logging"
 	"dashboard-server/routes"
+	"dashboard-server/services"
+	"dashboard-server/sysinfo"
+	"dashboard-server/trash"
 
 	"github.com/joho/godotenv"
 )
 
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using default configuration")
+	envErr := godotenv.Load()
+	configFile := os.Getenv("CONFIG_FILE")
+	var configErr error
+	if configFile != "" {
+		configErr = godotenv.Load(configFile)
+	}
+
+	logging.Setup()
+	if envErr != nil {
+		slog.Info("No .env file found, using default configuration")
+	}
+	if configErr != nil {
+		logging.Fatal("Failed to load config file", "path", configFile, "error", configErr)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(os.Args) > 1 && os.Args[1] == "agent" {
+		runAgent(ctx)
+		return
 	}
 
 	database.InitDatabase()
+	services.StartWorker(ctx, "trash-purge", func(ctx context.Context) {
+		trash.RunPurger(ctx, database.DB)
+	})
+	services.StartWorker(ctx, "system-stats", sysinfo.Local().Run)
+	if config.Bool("DOCKER_DISCOVERY", false) {
+		client, err := services.NewDockerClient(config.String("DOCKER_DISCOVERY_ENDPOINT", services.DefaultDockerEndpoint), false)
+		if err != nil {
+			logging.Fatal("Invalid Docker discovery endpoint", "error", err)
+		}
+		services.StartWorker(ctx, "docker-discovery", func(ctx context.Context) {
+			discovery.RunDocker(ctx, database.DB, client, config.Duration("DOCKER_DISCOVERY_INTERVAL", 5*time.Minute))
+		})
+	}
+	if config.Bool("KUBERNETES_DISCOVERY", false) {
+		services.StartWorker(ctx, "kubernetes-discovery", func(ctx context.Context) {
+			discovery.RunKubernetes(ctx, database.DB, os.Getenv("KUBERNETES_DISCOVERY_CONTEXT"), config.Duration("KUBERNETES_DISCOVERY_INTERVAL", time.Minute))
+		})
+	}
+
 	r := routes.SetupRoutes()
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	port := config.String("PORT", "8080")
+
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	var redirectSrv *http.Server
+	certFile, keyFile := os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE")
+	tlsEnabled := certs.Enabled()
+
+	if tlsEnabled {
+		reloader, err := certs.NewReloader(certFile, keyFile)
+		if err != nil {
+			logging.Fatal("Failed to load TLS certificate", "error", err)
+		}
+
+		srv.TLSConfig, err = certs.ServerConfig(reloader, os.Getenv("TLS_CLIENT_CA_FILE"), certs.ClientAuthMode(os.Getenv("TLS_CLIENT_AUTH")))
+		if err != nil {
+			logging.Fatal("Invalid TLS configuration", "error", err)
+		}
+
+		services.StartWorker(ctx, "tls-reloader", func(ctx context.Context) {
+			reloader.Watch(ctx, config.Duration("TLS_RELOAD_INTERVAL", 30*time.Second))
+		})
+
+		if redirectPort := os.Getenv("TLS_REDIRECT_PORT"); redirectPort != "" {
+			redirectSrv = &http.Server{
+				Addr:              ":" + redirectPort,
+				Handler:           httpsRedirect(port),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+		}
+	}
+
+	go func() {
+		var err error
+		if tlsEnabled {
+			slog.Info("Server starting with TLS", "port", port)
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			slog.Info("Server starting", "port", port)
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Fatal("Failed to start server", "error", err)
+		}
+	}()
+
+	if redirectSrv != nil {
+		go func() {
+			slog.Info("HTTP to HTTPS redirect listening", "addr", redirectSrv.Addr)
+			if err := redirectSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logging.Fatal("Failed to start redirect server", "error", err)
+			}
+		}()
 	}
 
-	log.Printf("Server starting on port %s", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	<-ctx.Done()
+	stop()
+	slog.Info("Shutdown signal received, draining requests")
+
+	controllers.MarkShuttingDown()
+	timeout := config.Duration("SHUTDOWN_TIMEOUT", 15*time.Second)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if redirectSrv != nil {
+		redirectSrv.Shutdown(shutdownCtx)
 	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Server shutdown did not complete cleanly", "error", err)
+	}
+
+	if !services.WaitForWorkers(timeout) {
+		slog.Warn("Timed out waiting for background workers to stop")
+	}
+
+	if err := database.Close(); err != nil {
+		slog.Error("Failed to close database", "error", err)
+	}
+
+	slog.Info("Server stopped")
+}
+
+// httpsRedirect sends plain HTTP clients to the same host on the TLS port.
+func httpsRedirect(tlsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if tlsPort != "443" {
+			host = net.JoinHostPort(host, tlsPort)
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
 }
@@ -0,0 +1,17 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+func Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", contentType)
+		Default.Write(c.Writer)
+	}
+}
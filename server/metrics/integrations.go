@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+var (
+	HTTPRequestsTotal = Default.NewCounterVec(
+		"neon_bridge_http_requests_total",
+		"Total HTTP requests handled, by route, method and status code.",
+		"route", "method", "status",
+	)
+	HTTPRequestDuration = Default.NewHistogramVec(
+		"neon_bridge_http_request_duration_seconds",
+		"HTTP request latency by route and method.",
+		DefaultBuckets,
+		"route", "method",
+	)
+
+	integrationFetchDuration = Default.NewHistogramVec(
+		"neon_bridge_integration_fetch_duration_seconds",
+		"Time spent fetching data from an upstream integration.",
+		DefaultBuckets,
+		"integration",
+	)
+	integrationFetchErrors = Default.NewCounterVec(
+		"neon_bridge_integration_fetch_errors_total",
+		"Number of failed fetches from an upstream integration.",
+		"integration",
+	)
+	integrationLastSuccess = Default.NewGaugeVec(
+		"neon_bridge_integration_last_success_timestamp_seconds",
+		"Unix time of the last successful fetch from an upstream integration.",
+		"integration",
+	)
+
+	widgetValues = Default.NewGaugeVec(
+		"neon_bridge_widget_value",
+		"Latest value fetched for a widget, labelled by widget and field.",
+		"widget_id", "widget_name", "widget_type", "field",
+	)
+)
+
+// ObserveFetch records the outcome of one upstream fetch started at start.
+func ObserveFetch(integration string, start time.Time, err error) {
+	integrationFetchDuration.Observe(time.Since(start).Seconds(), integration)
+	if err != nil {
+		integrationFetchErrors.Inc(integration)
+		return
+	}
+	integrationLastSuccess.Set(float64(time.Now().Unix()), integration)
+}
+
+// SetWidgetValues replaces the exported values of a widget with fields.
+func SetWidgetValues(widgetID uint, widgetName, widgetType string, fields map[string]float64) {
+	id := strconv.FormatUint(uint64(widgetID), 10)
+	widgetValues.DeleteMatching(0, id)
+	for field, value := range fields {
+		widgetValues.Set(value, id, widgetName, widgetType, field)
+	}
+}
+
+func DeleteWidgetValues(widgetID uint) {
+	widgetValues.DeleteMatching(0, strconv.FormatUint(uint64(widgetID), 10))
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch\n--- got\n%s\n--- want\n%s", name, got, want)
+	}
+}
+
+func TestExposition(t *testing.T) {
+	r := NewRegistry()
+
+	requests := r.NewCounterVec("test_requests_total", "Requests handled.\nSecond line with a \\ backslash.", "route", "status")
+	requests.Inc("/api/v1/widgets", "200")
+	requests.Add(2, "/api/v1/widgets", "200")
+	requests.Inc(`/quote"d`, "500")
+	requests.Inc("back\\slash\nnewline", "404")
+	requests.Add(-1, "/api/v1/widgets", "200")
+
+	temperature := r.NewGaugeVec("test_temperature_celsius", "Temperature.", "sensor")
+	temperature.Set(41.5, "cpu")
+	temperature.Set(math.Inf(1), "broken")
+	temperature.Set(math.NaN(), "missing")
+	temperature.Set(1, "gone")
+	temperature.DeleteMatching(0, "gone")
+
+	latency := r.NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 0.5, 1}, "integration")
+	for _, value := range []float64{0.05, 0.1, 0.3, 0.75, 2} {
+		latency.Observe(value, "sonarr")
+	}
+	latency.Observe(0.2, "radarr")
+
+	r.NewGaugeFunc("test_up", "Always one.", func() float64 { return 1 })
+
+	var out bytes.Buffer
+	r.Write(&out)
+	golden(t, "exposition.golden", out.Bytes())
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	r := NewRegistry()
+	counter := r.NewCounterVec("test_total", "Test.", "a", "b")
+	histogram := r.NewHistogramVec("test_seconds", "Test.", DefaultBuckets, "a")
+
+	for name, fn := range map[string]func(){
+		"counter":   func() { counter.Inc("only one") },
+		"histogram": func() { histogram.Observe(1, "one", "two") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			fn()
+		})
+	}
+}
+
+func TestObserveFetch(t *testing.T) {
+	ObserveFetch("test-integration", time.Now(), nil)
+	ObserveFetch("test-integration", time.Now(), errors.New("boom"))
+
+	var out bytes.Buffer
+	Default.Write(&out)
+	for _, want := range []string{
+		`neon_bridge_integration_fetch_duration_seconds_count{integration="test-integration"} 2`,
+		`neon_bridge_integration_fetch_errors_total{integration="test-integration"} 1`,
+		`neon_bridge_integration_last_success_timestamp_seconds{integration="test-integration"} `,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("exposition is missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"io"
+	"runtime"
+	"time"
+)
+
+var processStart = time.Now()
+
+type runtimeCollector struct{}
+
+func (runtimeCollector) write(w io.Writer) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	writeSample(w, "go_goroutines", "Number of goroutines that currently exist.", gaugeType, float64(runtime.NumGoroutine()))
+	writeSample(w, "go_threads", "Number of OS threads created.", gaugeType, float64(threadCount()))
+	writeSample(w, "go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", gaugeType, float64(mem.Alloc))
+	writeSample(w, "go_memstats_alloc_bytes_total", "Total number of bytes allocated, even if freed.", counterType, float64(mem.TotalAlloc))
+	writeSample(w, "go_memstats_sys_bytes", "Number of bytes obtained from system.", gaugeType, float64(mem.Sys))
+	writeSample(w, "go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", gaugeType, float64(mem.HeapInuse))
+	writeSample(w, "go_memstats_heap_objects", "Number of allocated objects.", gaugeType, float64(mem.HeapObjects))
+	writeSample(w, "go_memstats_stack_inuse_bytes", "Number of bytes in use by the stack allocator.", gaugeType, float64(mem.StackInuse))
+	writeSample(w, "go_gc_cycles_total", "Number of completed GC cycles.", counterType, float64(mem.NumGC))
+	writeSample(w, "go_gc_pause_seconds_total", "Total time spent in GC stop-the-world pauses.", counterType, float64(mem.PauseTotalNs)/1e9)
+	writeSample(w, "process_start_time_seconds", "Start time of the process since unix epoch in seconds.", gaugeType, float64(processStart.Unix()))
+}
+
+func threadCount() int {
+	n, _ := runtime.ThreadCreateProfile(nil)
+	return n
+}
+
+func writeSample(w io.Writer, name, help string, kind metricType, value float64) {
+	writeHeader(w, name, help, kind)
+	io.WriteString(w, name+" "+formatValue(value)+"\n")
+}
+
+func init() {
+	Default.register(runtimeCollector{})
+}
@@ -1,16 +1,49 @@
 package middleware
 
 import (
+	"log/slog"
+	"os"
+	"strings"
+
+	"dashboard-server/config"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	defaultAllowedOrigins = []string{"http://localhost:5173", "http://localhost:3000", "http://localhost:3200", "http://localhost:8080"}
+	defaultAllowedMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	defaultAllowedHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"}
+)
+
+// CORSEnabled reports whether cross-origin requests should be allowed at all.
+// Setting CORS_ALLOWED_ORIGINS to an empty value, or serving the UI from this
+// server without configuring origins, disables CORS entirely.
+func CORSEnabled(serveUI bool) bool {
+	origins, ok := os.LookupEnv("CORS_ALLOWED_ORIGINS")
+	if !ok {
+		return !serveUI
+	}
+	return strings.TrimSpace(origins) != ""
+}
+
 func CORS() gin.HandlerFunc {
-	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{"http://localhost:5173", "http://localhost:3000", "http://localhost:3200", "http://localhost:8080"}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-	config.AllowCredentials = true
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowMethods = config.List("CORS_ALLOWED_METHODS", defaultAllowedMethods)
+	corsConfig.AllowHeaders = config.List("CORS_ALLOWED_HEADERS", defaultAllowedHeaders)
+	corsConfig.AllowCredentials = config.Bool("CORS_ALLOW_CREDENTIALS", true)
+
+	origins := config.List("CORS_ALLOWED_ORIGINS", defaultAllowedOrigins)
+	if len(origins) == 1 && origins[0] == "*" {
+		corsConfig.AllowAllOrigins = true
+		if corsConfig.AllowCredentials {
+			slog.Warn("CORS_ALLOW_CREDENTIALS is ignored when all origins are allowed")
+			corsConfig.AllowCredentials = false
+		}
+	} else {
+		corsConfig.AllowOrigins = origins
+	}
 
-	return cors.New(config)
+	return cors.New(corsConfig)
 }
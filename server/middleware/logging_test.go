@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"dashboard-server/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoggerRedactsShareToken(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, "info", "text"))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Logger())
+	r.GET("/api/v1/share/:token/dashboard", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	const token = "c2hhcmUtdG9rZW4tc2VjcmV0"
+	for _, path := range []string{
+		"/api/v1/share/" + token + "/dashboard",
+		"/api/v1/share/" + token + "/no/such/route",
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if strings.Contains(buf.String(), token) {
+		t.Errorf("log contains the share token:\n%s", buf.String())
+	}
+	if got := strings.Count(buf.String(), "/api/v1/share/[REDACTED]/"); got != 2 {
+		t.Errorf("%d redacted share paths logged, want 2:\n%s", got, buf.String())
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+
+	"dashboard-server/config"
+	"dashboard-server/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrustedProxies parses TRUSTED_PROXIES (IPs or CIDRs). Only requests whose
+// direct peer is in this list may override the client IP, scheme or host.
+func TrustedProxies() []string {
+	return config.List("TRUSTED_PROXIES", nil)
+}
+
+// ForwardedHeaders applies X-Forwarded-Proto and X-Forwarded-Host from
+// trusted proxies so absolute URLs built by the server match what the
+// client sees.
+//
+// Forwarded headers from any other peer are ignored, which makes every client
+// behind an unlisted proxy share that proxy's IP and rate limit bucket. The
+// first such request is logged so the misconfiguration is visible.
+func ForwardedHeaders(trustedProxies []string) gin.HandlerFunc {
+	networks := security.ParseNetworks(trustedProxies)
+	var warnOnce sync.Once
+
+	return func(c *gin.Context) {
+		peer := net.ParseIP(c.RemoteIP())
+		if peer == nil || !security.ContainsIP(networks, peer) {
+			if c.GetHeader("X-Forwarded-For") != "" {
+				warnOnce.Do(func() {
+					slog.Warn("Ignoring X-Forwarded-For from a peer not in TRUSTED_PROXIES; clients behind it share one rate limit", "peer", c.RemoteIP())
+				})
+			}
+			c.Next()
+			return
+		}
+
+		if proto := firstForwardedValue(c.GetHeader("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+			c.Set("forwarded_proto", proto)
+		}
+		if host := firstForwardedValue(c.GetHeader("X-Forwarded-Host")); host != "" {
+			c.Request.Host = host
+		}
+
+		c.Next()
+	}
+}
+
+func firstForwardedValue(header string) string {
+	value, _, _ := strings.Cut(header, ",")
+	return strings.TrimSpace(value)
+}
+
+func RequestScheme(c *gin.Context) string {
+	if proto := c.GetString("forwarded_proto"); proto != "" {
+		return proto
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// ExternalURL builds the absolute URL the client would use to reach path on
+// this server, including the configured base path.
+func ExternalURL(c *gin.Context, path string) string {
+	return fmt.Sprintf("%s://%s%s%s", RequestScheme(c), c.Request.Host, config.BasePath(), path)
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"dashboard-server/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Identity records who is calling. The user comes from a verified client
+// certificate, or from AUTH_USER_HEADER when the request arrives through a
+// trusted proxy that performs authentication. Outbound requests made for the
+// caller are tagged with the user, or the client IP when there is none.
+func Identity(trustedProxies []string) gin.HandlerFunc {
+	networks := security.ParseNetworks(trustedProxies)
+	userHeader := os.Getenv("AUTH_USER_HEADER")
+
+	return func(c *gin.Context) {
+		user := ""
+		if c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 {
+			user = c.Request.TLS.VerifiedChains[0][0].Subject.CommonName
+		}
+		if user == "" && userHeader != "" {
+			if peer := net.ParseIP(c.RemoteIP()); peer != nil && security.ContainsIP(networks, peer) {
+				user = c.GetHeader(userHeader)
+			}
+		}
+
+		client := "ip:" + c.ClientIP()
+		if user != "" {
+			c.Set("user", user)
+			client = "user:" + user
+		}
+		c.Request = c.Request.WithContext(security.WithClient(c.Request.Context(), client))
+
+		c.Next()
+	}
+}
+
+// RateLimit applies perIP to every request and perUser to requests with a
+// known user. Either limiter may be nil to disable it.
+func RateLimit(perIP, perUser *security.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, wait := perIP.Allow(c.ClientIP())
+		if allowed {
+			if user := c.GetString("user"); user != "" {
+				allowed, wait = perUser.Allow(user)
+			}
+		}
+
+		if !allowed {
+			retryAfter := int(wait.Round(time.Second) / time.Second)
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, slow down"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dashboard-server/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+func rateLimitedEngine(t *testing.T, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatal(err)
+	}
+	r.Use(ForwardedHeaders(trustedProxies))
+	r.Use(Identity(trustedProxies))
+	r.Use(RateLimit(security.NewLimiter(60, 1), nil))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func requestFrom(r *gin.Engine, peer, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = peer + ":40000"
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimitPerClientBehindTrustedProxy(t *testing.T) {
+	r := rateLimitedEngine(t, []string{"172.30.0.10"})
+
+	if code := requestFrom(r, "172.30.0.10", "192.168.1.50"); code != http.StatusOK {
+		t.Fatalf("first client: status %d", code)
+	}
+	if code := requestFrom(r, "172.30.0.10", "192.168.1.51"); code != http.StatusOK {
+		t.Errorf("second client behind the proxy was limited with the first: status %d", code)
+	}
+	if code := requestFrom(r, "172.30.0.10", "192.168.1.50"); code != http.StatusTooManyRequests {
+		t.Errorf("first client over its limit: status %d", code)
+	}
+}
+
+func TestRateLimitIgnoresUntrustedForwardedFor(t *testing.T) {
+	r := rateLimitedEngine(t, nil)
+
+	if code := requestFrom(r, "203.0.113.9", "192.168.1.50"); code != http.StatusOK {
+		t.Fatalf("first request: status %d", code)
+	}
+	// A spoofed header must not buy a fresh bucket.
+	if code := requestFrom(r, "203.0.113.9", "192.168.1.51"); code != http.StatusTooManyRequests {
+		t.Errorf("spoofed X-Forwarded-For escaped the limit: status %d", code)
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrAuditLogImmutable = errors.New("audit log entries cannot be modified or deleted")
+
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+	// AuditActionControl records actions a widget performs on the service it
+	// monitors, such as restarting a container.
+	AuditActionControl = "control"
+)
+
+// AuditLog is an append-only record of a configuration change. Changes maps
+// each changed field to its old and new value, with secrets redacted.
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+	Actor      string    `json:"actor" gorm:"index"`
+	SourceIP   string    `json:"source_ip"`
+	RequestID  string    `json:"request_id"`
+	Action     string    `json:"action" gorm:"not null;index"`
+	EntityType string    `json:"entity_type" gorm:"not null;index:idx_audit_entity"`
+	EntityID   uint      `json:"entity_id" gorm:"index:idx_audit_entity"`
+	Changes    JSON      `json:"changes" gorm:"type:text"`
+}
+
+func (a *AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
+
+func (a *AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogImmutable
+}
@@ -11,11 +11,13 @@ type Dashboard struct {
 	Name          string         `json:"name" gorm:"not null"`
 	Description   string         `json:"description"`
 	GlancesConfig string         `json:"glances_config" gorm:"type:json"`
+	Columns       LayoutColumns  `json:"columns" gorm:"type:text"`
 	CreatedAt     time.Time      `json:"created_at"`
 	UpdatedAt     time.Time      `json:"updated_at"`
 	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
 
-	Widgets []Widget `json:"widgets" gorm:"foreignKey:DashboardID"`
+	Widgets  []Widget  `json:"widgets" gorm:"foreignKey:DashboardID"`
+	Sections []Section `json:"sections" gorm:"foreignKey:DashboardID"`
 }
 
 type DashboardResponse struct {
@@ -23,6 +25,8 @@ type DashboardResponse struct {
 	Name          string           `json:"name"`
 	Description   string           `json:"description"`
 	GlancesConfig string           `json:"glances_config"`
+	Columns       LayoutColumns    `json:"columns"`
+	Sections      []Section        `json:"sections"`
 	CreatedAt     time.Time        `json:"created_at"`
 	UpdatedAt     time.Time        `json:"updated_at"`
 	Widgets       []WidgetResponse `json:"widgets"`
@@ -39,6 +43,8 @@ func (d *Dashboard) ToResponse() DashboardResponse {
 		Name:          d.Name,
 		Description:   d.Description,
 		GlancesConfig: d.GlancesConfig,
+		Columns:       d.Columns.Resolved(),
+		Sections:      d.Sections,
 		CreatedAt:     d.CreatedAt,
 		UpdatedAt:     d.UpdatedAt,
 		Widgets:       widgetResponses,
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+const (
+	HostTypeGlances = "glances"
+	HostTypeAgent   = "agent"
+)
+
+// MonitoredHost is a machine running Glances or the server in agent mode.
+// Agents authenticate with Password as a bearer token and ignore Username.
+type MonitoredHost struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null;uniqueIndex"`
+	Type      string    `json:"type" gorm:"not null;default:glances"`
+	URL       string    `json:"url" gorm:"not null"`
+	Username  string    `json:"username"`
+	Password  string    `json:"-"`
+	Enabled   bool      `json:"enabled"` // no default: GORM would insert it in place of false
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type MonitoredHostResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	URL         string    `json:"url"`
+	Username    string    `json:"username"`
+	HasPassword bool      `json:"has_password"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (h *MonitoredHost) ToResponse() MonitoredHostResponse {
+	return MonitoredHostResponse{
+		ID:          h.ID,
+		Name:        h.Name,
+		Type:        h.Type,
+		URL:         h.URL,
+		Username:    h.Username,
+		HasPassword: h.Password != "",
+		Enabled:     h.Enabled,
+		CreatedAt:   h.CreatedAt,
+		UpdatedAt:   h.UpdatedAt,
+	}
+}
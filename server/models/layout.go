@@ -0,0 +1,110 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	BreakpointDesktop = "desktop"
+	BreakpointTablet  = "tablet"
+	BreakpointMobile  = "mobile"
+)
+
+var Breakpoints = []string{BreakpointDesktop, BreakpointTablet, BreakpointMobile}
+
+var DefaultColumns = LayoutColumns{
+	BreakpointDesktop: 12,
+	BreakpointTablet:  8,
+	BreakpointMobile:  4,
+}
+
+// LayoutColumns is the grid width of a dashboard per breakpoint.
+type LayoutColumns map[string]int
+
+func (l *LayoutColumns) Scan(value interface{}) error {
+	return scanJSONText(value, l)
+}
+
+func (l LayoutColumns) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// For returns the column count for a breakpoint, falling back to the
+// defaults for breakpoints the dashboard does not override.
+func (l LayoutColumns) For(breakpoint string) int {
+	if columns, ok := l[breakpoint]; ok && columns > 0 {
+		return columns
+	}
+	return DefaultColumns[breakpoint]
+}
+
+// Resolved returns a complete copy with defaults filled in.
+func (l LayoutColumns) Resolved() LayoutColumns {
+	resolved := LayoutColumns{}
+	for _, breakpoint := range Breakpoints {
+		resolved[breakpoint] = l.For(breakpoint)
+	}
+	return resolved
+}
+
+type GridRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+func (r GridRect) Overlaps(other GridRect) bool {
+	return r.X < other.X+other.W && other.X < r.X+r.W &&
+		r.Y < other.Y+other.H && other.Y < r.Y+r.H
+}
+
+// WidgetLayout places a widget on the grid, per breakpoint.
+type WidgetLayout map[string]GridRect
+
+func (l *WidgetLayout) Scan(value interface{}) error {
+	return scanJSONText(value, l)
+}
+
+func (l WidgetLayout) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+func scanJSONText(value interface{}, out interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into %T", value, out)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// Section groups widgets on a dashboard under a collapsible title. Widgets
+// without a section belong to the dashboard's top-level grid.
+type Section struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	DashboardID uint      `json:"dashboard_id" gorm:"not null;index"`
+	Title       string    `json:"title"`
+	Position    int       `json:"position" gorm:"default:0"`
+	Collapsed   bool      `json:"collapsed" gorm:"default:false"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// AnonymousUser keys the preferences of requests without a known user, so a
+// single-user install still has somewhere to keep them.
+const AnonymousUser = "anonymous"
+
+type UserPreference struct {
+	User               string    `json:"user" gorm:"primaryKey"`
+	DefaultDashboardID *uint     `json:"default_dashboard_id"`
+	Theme              string    `json:"theme"`
+	RefreshInterval    int       `json:"refresh_interval"`
+	Timezone           string    `json:"timezone"`
+	Units              string    `json:"units"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// DefaultPreferences returns the preferences used before a user saves any.
+func DefaultPreferences(user string) UserPreference {
+	return UserPreference{
+		User:            user,
+		Theme:           "system",
+		RefreshInterval: 30,
+		Timezone:        "Local",
+		Units:           "metric",
+	}
+}
+
+func (p *UserPreference) Validate() error {
+	switch p.Theme {
+	case "system", "light", "dark":
+	default:
+		return fmt.Errorf("invalid theme %q, expected system, light or dark", p.Theme)
+	}
+	switch p.Units {
+	case "metric", "imperial":
+	default:
+		return fmt.Errorf("invalid units %q, expected metric or imperial", p.Units)
+	}
+	if p.RefreshInterval < 5 || p.RefreshInterval > 86400 {
+		return fmt.Errorf("refresh_interval must be between 5 and 86400 seconds")
+	}
+	if _, err := time.LoadLocation(p.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q", p.Timezone)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Revision is a full snapshot of a widget or dashboard taken on every save.
+// Dashboard snapshots embed their widgets so a whole board can be rolled
+// back. Snapshots keep secrets so a rollback restores working credentials;
+// ToResponse strips them again.
+type Revision struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	EntityType string    `json:"entity_type" gorm:"not null;uniqueIndex:idx_revision_version"`
+	EntityID   uint      `json:"entity_id" gorm:"not null;uniqueIndex:idx_revision_version"`
+	Version    int       `json:"version" gorm:"not null;uniqueIndex:idx_revision_version"`
+	Actor      string    `json:"actor"`
+	Snapshot   JSON      `json:"snapshot" gorm:"type:text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type RevisionResponse struct {
+	ID         uint         `json:"id"`
+	EntityType string       `json:"entity_type"`
+	EntityID   uint         `json:"entity_id"`
+	Version    int          `json:"version"`
+	Actor      string       `json:"actor"`
+	Snapshot   FilteredJSON `json:"snapshot,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+func (r *Revision) ToResponse(includeSnapshot bool) RevisionResponse {
+	response := RevisionResponse{
+		ID:         r.ID,
+		EntityType: r.EntityType,
+		EntityID:   r.EntityID,
+		Version:    r.Version,
+		Actor:      r.Actor,
+		CreatedAt:  r.CreatedAt,
+	}
+	if includeSnapshot {
+		response.Snapshot = FilteredJSON(filterSensitiveSnapshot(map[string]interface{}(r.Snapshot)))
+	}
+	return response
+}
+
+// filterSensitiveSnapshot is filterSensitiveFields that also descends into
+// the embedded widget list and Glances config of dashboard snapshots.
+func filterSensitiveSnapshot(data map[string]interface{}) map[string]interface{} {
+	filtered := filterSensitiveFields(data)
+	if raw, ok := data["glances_config"].(string); ok && raw != "" {
+		filtered["glances_config"] = filterGlancesConfig(raw)
+	}
+	if widgets, ok := data["widgets"].([]interface{}); ok {
+		list := make([]interface{}, 0, len(widgets))
+		for _, widget := range widgets {
+			if widgetMap, ok := widget.(map[string]interface{}); ok {
+				list = append(list, filterSensitiveFields(widgetMap))
+			}
+		}
+		filtered["widgets"] = list
+	}
+	return filtered
+}
+
+// filterGlancesConfig drops the credentials from a dashboard's Glances
+// config, which is stored as a JSON string. Anything unparsable is blanked.
+func filterGlancesConfig(raw string) string {
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &config); err != nil {
+		return ""
+	}
+	filtered, err := json.Marshal(filterSensitiveFields(config))
+	if err != nil {
+		return ""
+	}
+	return string(filtered)
+}
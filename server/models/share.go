@@ -0,0 +1,86 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// IDList is a list of IDs stored as a JSON array.
+type IDList []uint
+
+func (l *IDList) Scan(value interface{}) error {
+	return scanJSONText(value, l)
+}
+
+func (l IDList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+func (l IDList) Contains(id uint) bool {
+	for _, item := range l {
+		if item == id {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldRules maps a widget ID, or "*" for every widget, to dotted field
+// paths that are removed before data leaves the server.
+type FieldRules map[string][]string
+
+func (r *FieldRules) Scan(value interface{}) error {
+	return scanJSONText(value, r)
+}
+
+func (r FieldRules) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+// ShareLink grants read-only access to one dashboard without an account.
+// The token itself is never stored: it is derived from the ID and Nonce
+// with a server-side key, so rotating the nonce invalidates old links.
+type ShareLink struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	DashboardID   uint       `json:"dashboard_id" gorm:"not null;index"`
+	Name          string     `json:"name"`
+	Nonce         string     `json:"-" gorm:"not null"`
+	HiddenWidgets IDList     `json:"hidden_widgets" gorm:"type:text"`
+	HiddenFields  FieldRules `json:"hidden_fields" gorm:"type:text"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	UseCount      int64      `json:"use_count" gorm:"default:0"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	LastUsedIP    string     `json:"last_used_ip"`
+	CreatedBy     string     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (s *ShareLink) Active(now time.Time) bool {
+	if s.RevokedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || now.Before(*s.ExpiresAt)
+}
+
+// FieldsFor returns the hidden field paths that apply to a widget.
+func (s *ShareLink) FieldsFor(widgetID uint) []string {
+	fields := append([]string(nil), s.HiddenFields["*"]...)
+	return append(fields, s.HiddenFields[strconv.FormatUint(uint64(widgetID), 10)]...)
+}
+
+// AppSecret holds keys the server generates for itself on first use.
+type AppSecret struct {
+	Name      string `gorm:"primaryKey"`
+	Value     string `gorm:"not null"`
+	CreatedAt time.Time
+}
@@ -36,6 +36,23 @@ func (j JSON) Value() (driver.Value, error) {
 	return json.Marshal(j)
 }
 
+// Clone returns a deep copy, so a copied widget never shares nested maps
+// with the original.
+func (j JSON) Clone() JSON {
+	if j == nil {
+		return nil
+	}
+	data, err := json.Marshal(j)
+	if err != nil {
+		return nil
+	}
+	clone := make(JSON)
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil
+	}
+	return clone
+}
+
 type FilteredJSON map[string]interface{}
 
 var sensitiveFields = []string{
@@ -53,20 +70,21 @@ var sensitiveFields = []string{
 	"refresh_token",
 }
 
+func IsSensitiveField(key string) bool {
+	lowerKey := strings.ToLower(key)
+	for _, sensitiveField := range sensitiveFields {
+		if strings.Contains(lowerKey, sensitiveField) {
+			return true
+		}
+	}
+	return false
+}
+
 func filterSensitiveFields(data map[string]interface{}) map[string]interface{} {
 	filtered := make(map[string]interface{})
 
 	for key, value := range data {
-		isSensitive := false
-		lowerKey := strings.ToLower(key)
-		for _, sensitiveField := range sensitiveFields {
-			if strings.Contains(lowerKey, sensitiveField) {
-				isSensitive = true
-				break
-			}
-		}
-
-		if !isSensitive {
+		if !IsSensitiveField(key) {
 			if nestedMap, ok := value.(map[string]interface{}); ok {
 				filtered[key] = filterSensitiveFields(nestedMap)
 			} else {
@@ -79,17 +97,23 @@ func filterSensitiveFields(data map[string]interface{}) map[string]interface{} {
 }
 
 type Widget struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	DashboardID uint           `json:"dashboard_id" gorm:"not null;index"`
-	Name        string         `json:"name" gorm:"not null"`
-	Type        string         `json:"type" gorm:"not null"`
-	Position    int            `json:"position" gorm:"default:0"`
-	Config      JSON           `json:"config" gorm:"type:text"`
-	LastState   JSON           `json:"last_state" gorm:"type:text"`
-	IsEnabled   bool           `json:"is_enabled" gorm:"default:true"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	DashboardID uint         `json:"dashboard_id" gorm:"not null;index"`
+	Name        string       `json:"name" gorm:"not null"`
+	Type        string       `json:"type" gorm:"not null"`
+	Position    int          `json:"position" gorm:"default:0"`
+	SectionID   *uint        `json:"section_id" gorm:"index"`
+	Layout      WidgetLayout `json:"layout" gorm:"type:text"`
+	Config      JSON         `json:"config" gorm:"type:text"`
+	LastState   JSON         `json:"last_state" gorm:"type:text"`
+	IsEnabled   bool         `json:"is_enabled"` // no default: GORM would insert it in place of false
+	Source      string       `json:"source" gorm:"index"`
+	// DisabledBySource marks a widget that discovery disabled because its
+	// service went away, so it is re-enabled only in that case.
+	DisabledBySource bool           `json:"-"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
 
 	Dashboard Dashboard `json:"dashboard" gorm:"foreignKey:DashboardID"`
 }
@@ -100,9 +124,12 @@ type WidgetResponse struct {
 	Name        string       `json:"name"`
 	Type        string       `json:"type"`
 	Position    int          `json:"position"`
+	SectionID   *uint        `json:"section_id"`
+	Layout      WidgetLayout `json:"layout"`
 	Config      FilteredJSON `json:"config"`
 	LastState   JSON         `json:"last_state"`
 	IsEnabled   bool         `json:"is_enabled"`
+	Source      string       `json:"source,omitempty"`
 	CreatedAt   time.Time    `json:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at"`
 }
@@ -114,9 +141,12 @@ func (w *Widget) ToResponse() WidgetResponse {
 		Name:        w.Name,
 		Type:        w.Type,
 		Position:    w.Position,
+		SectionID:   w.SectionID,
+		Layout:      w.Layout,
 		Config:      FilteredJSON(filterSensitiveFields(map[string]interface{}(w.Config))),
 		LastState:   w.LastState,
 		IsEnabled:   w.IsEnabled,
+		Source:      w.Source,
 		CreatedAt:   w.CreatedAt,
 		UpdatedAt:   w.UpdatedAt,
 	}
@@ -0,0 +1,486 @@
+package revisions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"dashboard-server/audit"
+	"dashboard-server/config"
+	"dashboard-server/layout"
+	"dashboard-server/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var ErrDashboardMissing = errors.New("the dashboard this revision belongs to no longer exists")
+
+type widgetSnapshot struct {
+	ID          uint                `json:"id"`
+	DashboardID uint                `json:"dashboard_id"`
+	Name        string              `json:"name"`
+	Type        string              `json:"type"`
+	Position    int                 `json:"position"`
+	IsEnabled   bool                `json:"is_enabled"`
+	SectionID   *uint               `json:"section_id"`
+	Layout      models.WidgetLayout `json:"layout"`
+	Config      models.JSON         `json:"config"`
+}
+
+type sectionSnapshot struct {
+	ID        uint   `json:"id"`
+	Title     string `json:"title"`
+	Position  int    `json:"position"`
+	Collapsed bool   `json:"collapsed"`
+}
+
+type dashboardSnapshot struct {
+	ID            uint                 `json:"id"`
+	Name          string               `json:"name"`
+	Description   string               `json:"description"`
+	GlancesConfig string               `json:"glances_config"`
+	Columns       models.LayoutColumns `json:"columns"`
+	Sections      []sectionSnapshot    `json:"sections"`
+	Widgets       []widgetSnapshot     `json:"widgets"`
+}
+
+func newWidgetSnapshot(w *models.Widget) widgetSnapshot {
+	return widgetSnapshot{
+		ID:          w.ID,
+		DashboardID: w.DashboardID,
+		Name:        w.Name,
+		Type:        w.Type,
+		Position:    w.Position,
+		IsEnabled:   w.IsEnabled,
+		SectionID:   w.SectionID,
+		Layout:      w.Layout,
+		Config:      w.Config,
+	}
+}
+
+func (s widgetSnapshot) apply(w *models.Widget) {
+	w.ID = s.ID
+	w.DashboardID = s.DashboardID
+	w.Name = s.Name
+	w.Type = s.Type
+	w.Position = s.Position
+	w.IsEnabled = s.IsEnabled
+	w.DisabledBySource = false
+	w.SectionID = s.SectionID
+	w.Layout = s.Layout
+	w.Config = s.Config
+}
+
+func toJSON(v interface{}) (models.JSON, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	result := models.JSON{}
+	return result, json.Unmarshal(data, &result)
+}
+
+func fromJSON(snapshot models.JSON, out interface{}) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// actor is "system" for changes made by background jobs, which have no
+// request.
+func actor(c *gin.Context) string {
+	if c == nil {
+		return "system"
+	}
+	if user := c.GetString("user"); user != "" {
+		return user
+	}
+	return "anonymous"
+}
+
+func save(tx *gorm.DB, c *gin.Context, entityType string, entityID uint, snapshot interface{}) error {
+	data, err := toJSON(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot %s %d: %w", entityType, entityID, err)
+	}
+
+	var latest int
+	if err := tx.Model(&models.Revision{}).
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+		return err
+	}
+
+	revision := models.Revision{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Version:    latest + 1,
+		Actor:      actor(c),
+		Snapshot:   data,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+
+	return prune(tx, entityType, entityID, revision.Version)
+}
+
+// prune keeps the newest REVISION_LIMIT revisions per entity.
+func prune(tx *gorm.DB, entityType string, entityID uint, latest int) error {
+	limit := config.Int("REVISION_LIMIT", 50)
+	if limit <= 0 || latest <= limit {
+		return nil
+	}
+	return tx.Where("entity_type = ? AND entity_id = ? AND version <= ?", entityType, entityID, latest-limit).
+		Delete(&models.Revision{}).Error
+}
+
+// SaveWidget snapshots w and the dashboard it belongs to, so dashboard
+// history also covers changes made one widget at a time.
+func SaveWidget(tx *gorm.DB, c *gin.Context, w *models.Widget) error {
+	if err := save(tx, c, audit.EntityWidget, w.ID, newWidgetSnapshot(w)); err != nil {
+		return err
+	}
+	return SaveDashboard(tx, c, w.DashboardID)
+}
+
+// SaveWidgets snapshots several widgets but each affected dashboard only
+// once, for bulk operations.
+func SaveWidgets(tx *gorm.DB, c *gin.Context, widgets []models.Widget, extraDashboards ...uint) error {
+	dashboards := make(map[uint]bool)
+	for i := range widgets {
+		if err := save(tx, c, audit.EntityWidget, widgets[i].ID, newWidgetSnapshot(&widgets[i])); err != nil {
+			return err
+		}
+		dashboards[widgets[i].DashboardID] = true
+	}
+	for _, id := range extraDashboards {
+		dashboards[id] = true
+	}
+
+	ids := make([]uint, 0, len(dashboards))
+	for id := range dashboards {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		if err := SaveDashboard(tx, c, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveDashboard snapshots the dashboard together with its current widgets.
+func SaveDashboard(tx *gorm.DB, c *gin.Context, dashboardID uint) error {
+	var dashboard models.Dashboard
+	if err := tx.Preload("Widgets").Preload("Sections").First(&dashboard, dashboardID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	snapshot := dashboardSnapshot{
+		ID:            dashboard.ID,
+		Name:          dashboard.Name,
+		Description:   dashboard.Description,
+		GlancesConfig: dashboard.GlancesConfig,
+		Columns:       dashboard.Columns,
+		Sections:      make([]sectionSnapshot, 0, len(dashboard.Sections)),
+		Widgets:       make([]widgetSnapshot, 0, len(dashboard.Widgets)),
+	}
+	for _, section := range dashboard.Sections {
+		snapshot.Sections = append(snapshot.Sections, sectionSnapshot{
+			ID:        section.ID,
+			Title:     section.Title,
+			Position:  section.Position,
+			Collapsed: section.Collapsed,
+		})
+	}
+	for i := range dashboard.Widgets {
+		snapshot.Widgets = append(snapshot.Widgets, newWidgetSnapshot(&dashboard.Widgets[i]))
+	}
+
+	return save(tx, c, audit.EntityDashboard, dashboard.ID, snapshot)
+}
+
+func Find(tx *gorm.DB, entityType string, entityID uint, version int) (*models.Revision, error) {
+	var revision models.Revision
+	err := tx.Where("entity_type = ? AND entity_id = ? AND version = ?", entityType, entityID, version).
+		First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// Diff compares two revisions field by field, with secrets redacted the
+// same way as in the audit log.
+func Diff(from, to *models.Revision) (models.JSON, error) {
+	before, err := flatten(from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := flatten(to)
+	if err != nil {
+		return nil, err
+	}
+	return audit.Diff(before, after), nil
+}
+
+func flatten(revision *models.Revision) (map[string]interface{}, error) {
+	if revision.EntityType == audit.EntityWidget {
+		var snapshot widgetSnapshot
+		if err := fromJSON(revision.Snapshot, &snapshot); err != nil {
+			return nil, err
+		}
+		widget := models.Widget{}
+		snapshot.apply(&widget)
+		return audit.WidgetFields(&widget), nil
+	}
+
+	var snapshot dashboardSnapshot
+	if err := fromJSON(revision.Snapshot, &snapshot); err != nil {
+		return nil, err
+	}
+	dashboard := models.Dashboard{Name: snapshot.Name, Description: snapshot.Description, GlancesConfig: snapshot.GlancesConfig, Columns: snapshot.Columns}
+	fields := audit.DashboardFields(&dashboard)
+	for _, ss := range snapshot.Sections {
+		section := models.Section{Title: ss.Title, Position: ss.Position, Collapsed: ss.Collapsed, DashboardID: snapshot.ID}
+		for key, value := range audit.SectionFields(&section) {
+			fields[fmt.Sprintf("sections.%d.%s", ss.ID, key)] = value
+		}
+	}
+	for _, ws := range snapshot.Widgets {
+		widget := models.Widget{}
+		ws.apply(&widget)
+		for key, value := range audit.WidgetFields(&widget) {
+			fields[fmt.Sprintf("widgets.%d.%s", ws.ID, key)] = value
+		}
+	}
+	return fields, nil
+}
+
+// RollbackWidget restores a widget, undeleting it if needed, and records
+// the result as a new revision.
+func RollbackWidget(tx *gorm.DB, c *gin.Context, revision *models.Revision) (*models.Widget, error) {
+	var snapshot widgetSnapshot
+	if err := fromJSON(revision.Snapshot, &snapshot); err != nil {
+		return nil, err
+	}
+
+	var dashboard models.Dashboard
+	if err := tx.First(&dashboard, snapshot.DashboardID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDashboardMissing
+		}
+		return nil, err
+	}
+
+	// The rest of the dashboard may have moved on since; keep the old
+	// placement only where it still fits.
+	placed := models.Widget{}
+	snapshot.apply(&placed)
+	if err := layout.PlaceInDashboard(tx, &placed); err != nil {
+		return nil, err
+	}
+	snapshot.SectionID = placed.SectionID
+	snapshot.Layout = placed.Layout
+
+	widget, err := restoreWidget(tx, c, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if err := SaveWidget(tx, c, widget); err != nil {
+		return nil, err
+	}
+	return widget, nil
+}
+
+// RollbackDashboard restores the dashboard fields and makes its widgets
+// match the snapshot: listed widgets are restored (or undeleted), others
+// are soft-deleted.
+func RollbackDashboard(tx *gorm.DB, c *gin.Context, revision *models.Revision) (*models.Dashboard, error) {
+	var snapshot dashboardSnapshot
+	if err := fromJSON(revision.Snapshot, &snapshot); err != nil {
+		return nil, err
+	}
+
+	var dashboard models.Dashboard
+	if err := tx.Unscoped().First(&dashboard, snapshot.ID).Error; err != nil {
+		return nil, err
+	}
+	before := audit.DashboardFields(&dashboard)
+	wasDeleted := dashboard.DeletedAt.Valid
+
+	dashboard.Name = snapshot.Name
+	dashboard.Description = snapshot.Description
+	dashboard.GlancesConfig = snapshot.GlancesConfig
+	dashboard.Columns = snapshot.Columns
+	dashboard.DeletedAt = gorm.DeletedAt{}
+	if err := tx.Unscoped().Save(&dashboard).Error; err != nil {
+		return nil, err
+	}
+
+	action := models.AuditActionUpdate
+	if wasDeleted {
+		action = models.AuditActionRestore
+	}
+	if err := audit.Record(tx, c, action, audit.EntityDashboard, dashboard.ID, before, audit.DashboardFields(&dashboard)); err != nil {
+		return nil, err
+	}
+
+	if err := restoreSections(tx, c, dashboard.ID, snapshot.Sections); err != nil {
+		return nil, err
+	}
+
+	keep := make(map[uint]bool, len(snapshot.Widgets))
+	for _, ws := range snapshot.Widgets {
+		ws.DashboardID = dashboard.ID
+		if _, err := restoreWidget(tx, c, ws); err != nil {
+			return nil, err
+		}
+		keep[ws.ID] = true
+	}
+
+	var current []models.Widget
+	if err := tx.Where("dashboard_id = ?", dashboard.ID).Find(&current).Error; err != nil {
+		return nil, err
+	}
+	for i := range current {
+		if keep[current[i].ID] {
+			continue
+		}
+		if err := tx.Delete(&current[i]).Error; err != nil {
+			return nil, err
+		}
+		if err := audit.Record(tx, c, models.AuditActionDelete, audit.EntityWidget, current[i].ID, audit.WidgetFields(&current[i]), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ws := range snapshot.Widgets {
+		var widget models.Widget
+		if err := tx.First(&widget, ws.ID).Error; err != nil {
+			return nil, err
+		}
+		if len(widget.Layout) < len(models.Breakpoints) {
+			// Snapshots from before layouts existed have no placement.
+			if err := layout.PlaceInDashboard(tx, &widget); err != nil {
+				return nil, err
+			}
+			if err := tx.Model(&widget).UpdateColumn("layout", widget.Layout).Error; err != nil {
+				return nil, err
+			}
+		}
+		if err := save(tx, c, audit.EntityWidget, widget.ID, newWidgetSnapshot(&widget)); err != nil {
+			return nil, err
+		}
+	}
+	if err := SaveDashboard(tx, c, dashboard.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Preload("Widgets").First(&dashboard, dashboard.ID).Error; err != nil {
+		return nil, err
+	}
+	return &dashboard, nil
+}
+
+// restoreSections makes the dashboard's sections match the snapshot,
+// recreating removed ones under their old IDs so widget references hold.
+func restoreSections(tx *gorm.DB, c *gin.Context, dashboardID uint, snapshots []sectionSnapshot) error {
+	var current []models.Section
+	if err := tx.Where("dashboard_id = ?", dashboardID).Find(&current).Error; err != nil {
+		return err
+	}
+	existing := make(map[uint]*models.Section, len(current))
+	for i := range current {
+		existing[current[i].ID] = &current[i]
+	}
+
+	keep := make(map[uint]bool, len(snapshots))
+	for _, ss := range snapshots {
+		keep[ss.ID] = true
+		section, ok := existing[ss.ID]
+
+		var before map[string]interface{}
+		action := models.AuditActionCreate
+		if ok {
+			before = audit.SectionFields(section)
+			action = models.AuditActionUpdate
+		} else {
+			section = &models.Section{ID: ss.ID, DashboardID: dashboardID}
+		}
+
+		section.Title = ss.Title
+		section.Position = ss.Position
+		section.Collapsed = ss.Collapsed
+		if err := tx.Save(section).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, c, action, audit.EntitySection, section.ID, before, audit.SectionFields(section)); err != nil {
+			return err
+		}
+	}
+
+	for i := range current {
+		if keep[current[i].ID] {
+			continue
+		}
+		if err := tx.Model(&models.Widget{}).Where("section_id = ?", current[i].ID).Update("section_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&current[i]).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, c, models.AuditActionDelete, audit.EntitySection, current[i].ID, audit.SectionFields(&current[i]), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreWidget writes a snapshot over the widget row with the same ID,
+// recreating or undeleting it as needed, and audits the change.
+func restoreWidget(tx *gorm.DB, c *gin.Context, snapshot widgetSnapshot) (*models.Widget, error) {
+	var widget models.Widget
+	err := tx.Unscoped().First(&widget, snapshot.ID).Error
+	exists := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var before map[string]interface{}
+	action := models.AuditActionCreate
+	if exists {
+		action = models.AuditActionUpdate
+		if widget.DeletedAt.Valid {
+			action = models.AuditActionRestore
+		} else {
+			before = audit.WidgetFields(&widget)
+		}
+	}
+
+	snapshot.apply(&widget)
+	widget.DeletedAt = gorm.DeletedAt{}
+	if exists {
+		err = tx.Unscoped().Save(&widget).Error
+	} else {
+		err = tx.Create(&widget).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := audit.Record(tx, c, action, audit.EntityWidget, widget.ID, before, audit.WidgetFields(&widget)); err != nil {
+		return nil, err
+	}
+	return &widget, nil
+}
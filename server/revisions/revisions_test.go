@@ -0,0 +1,57 @@
+package revisions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"dashboard-server/audit"
+	"dashboard-server/database"
+	"dashboard-server/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRollbackRecreatesDisabledWidget(t *testing.T) {
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dashboard := models.Dashboard{Name: "Home"}
+	if err := db.Omit("Sections").Create(&dashboard).Error; err != nil {
+		t.Fatal(err)
+	}
+	widget := models.Widget{DashboardID: dashboard.ID, Name: "Sonarr", Type: "sonarr"}
+	if err := db.Create(&widget).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&widget).Update("is_enabled", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveWidget(db, nil, &widget); err != nil {
+		t.Fatal(err)
+	}
+	revision, err := Find(db, audit.EntityWidget, widget.ID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Unscoped().Delete(&widget).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	restored, err := RollbackWidget(db, c, revision)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved models.Widget
+	if err := db.First(&saved, restored.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if saved.IsEnabled || restored.IsEnabled {
+		t.Errorf("rolled back widget is enabled (saved %v, returned %v)", saved.IsEnabled, restored.IsEnabled)
+	}
+}
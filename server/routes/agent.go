@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"dashboard-server/agent"
+	"dashboard-server/config"
+	"dashboard-server/controllers"
+	"dashboard-server/middleware"
+	"dashboard-server/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAgentRoutes serves the local collector to a main server. Only the
+// health check is open; everything else needs the agent token.
+func SetupAgentRoutes(token string) *gin.Engine {
+	r := gin.New()
+	trustedProxies := trustProxies(r)
+
+	r.Use(gin.Recovery())
+	r.Use(middleware.ForwardedHeaders(trustedProxies))
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Logger())
+
+	r.GET("/healthz", controllers.Healthz)
+
+	api := r.Group(agent.Path)
+	api.Use(middleware.RateLimit(
+		security.NewLimiter(config.Int("RATE_LIMIT_PER_MINUTE", 600), config.Int("RATE_LIMIT_BURST", 100)),
+		nil,
+	))
+	api.Use(middleware.RequireBearerToken(token))
+	{
+		api.GET("/stats", controllers.GetAgentStats)
+		api.GET("/details", controllers.GetAgentDetails)
+	}
+
+	return r
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dashboard-server/agent"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAgentRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("RATE_LIMIT_PER_MINUTE", "60")
+	t.Setenv("RATE_LIMIT_BURST", "5")
+	r := SetupAgentRoutes("agent-token")
+
+	limited := 0
+	for i := 0; i < 20; i++ {
+		req := httptest.NewRequest(http.MethodGet, agent.Path+"/stats", nil)
+		req.RemoteAddr = "203.0.113.7:40000"
+		req.Header.Set("Authorization", "Bearer guess")
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("10.0.0.%d", i))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code == http.StatusTooManyRequests {
+			limited++
+		}
+	}
+	if limited != 15 {
+		t.Errorf("%d of 20 guesses were rate limited, want 15", limited)
+	}
+}
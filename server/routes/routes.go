@@ -1,18 +1,69 @@
 package routes
 
 import (
+	"log/slog"
+	"os"
+
+	"dashboard-server/certs"
+	"dashboard-server/config"
 	"dashboard-server/controllers"
+	"dashboard-server/logging"
+	"dashboard-server/metrics"
 	"dashboard-server/middleware"
+	"dashboard-server/security"
+	"dashboard-server/web"
 
 	"github.com/gin-gonic/gin"
 )
 
+// trustProxies makes ClientIP honour forwarded headers only from
+// TRUSTED_PROXIES; gin trusts every peer by default.
+func trustProxies(r *gin.Engine) []string {
+	trustedProxies := middleware.TrustedProxies()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		logging.Fatal("Invalid TRUSTED_PROXIES", "error", err)
+	}
+	r.RemoteIPHeaders = config.List("REMOTE_IP_HEADERS", r.RemoteIPHeaders)
+	r.TrustedPlatform = os.Getenv("TRUSTED_PLATFORM")
+	return trustedProxies
+}
+
 func SetupRoutes() *gin.Engine {
-	r := gin.Default()
+	r := gin.New()
+	trustedProxies := trustProxies(r)
 
-	r.Use(middleware.CORS())
+	r.Use(gin.Recovery())
+	r.Use(middleware.ForwardedHeaders(trustedProxies))
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Logger())
 
-	v1 := r.Group("/api/v1")
+	serveUI := config.Bool("SERVE_UI", false)
+	if middleware.CORSEnabled(serveUI) {
+		r.Use(middleware.CORS())
+	}
+	r.Use(middleware.Metrics())
+
+	r.GET("/metrics", metrics.Handler())
+	r.GET("/healthz", controllers.Healthz)
+	r.GET("/readyz", controllers.Readyz)
+
+	basePath := config.BasePath()
+	v1 := r.Group(basePath + "/api/v1")
+	if certs.Enabled() && certs.ClientAuthMode(os.Getenv("TLS_CLIENT_AUTH")) == certs.ClientAuthAPI {
+		v1.Use(middleware.RequireClientCert())
+	}
+	v1.Use(middleware.Identity(trustedProxies))
+	v1.Use(middleware.RateLimit(
+		security.NewLimiter(config.Int("RATE_LIMIT_PER_MINUTE", 600), config.Int("RATE_LIMIT_BURST", 100)),
+		security.NewLimiter(config.Int("RATE_LIMIT_USER_PER_MINUTE", 600), config.Int("RATE_LIMIT_BURST", 100)),
+	))
+
+	// Test endpoints connect to caller-supplied URLs with caller-supplied
+	// credentials, so they get a much tighter budget.
+	testLimit := middleware.RateLimit(
+		security.NewLimiter(config.Int("RATE_LIMIT_TEST_PER_MINUTE", 10), config.Int("RATE_LIMIT_TEST_BURST", 5)),
+		security.NewLimiter(config.Int("RATE_LIMIT_TEST_PER_MINUTE", 10), config.Int("RATE_LIMIT_TEST_BURST", 5)),
+	)
 	{
 		dashboards := v1.Group("/dashboards")
 		{
@@ -21,9 +72,26 @@ func SetupRoutes() *gin.Engine {
 			dashboards.GET("/:id", controllers.GetDashboard)
 			dashboards.PUT("/:id", controllers.UpdateDashboard)
 			dashboards.DELETE("/:id", controllers.DeleteDashboard)
+			dashboards.POST("/:id/clone", controllers.CloneDashboard)
+
+			dashboards.GET("/:id/layout", controllers.GetDashboardLayout)
+			dashboards.PUT("/:id/layout", controllers.UpdateDashboardLayout)
+			dashboards.POST("/:id/sections", controllers.CreateSection)
+			dashboards.PUT("/:id/sections/:section_id", controllers.UpdateSection)
+			dashboards.DELETE("/:id/sections/:section_id", controllers.DeleteSection)
+
+			dashboards.GET("/:id/glances", controllers.GetGlancesStats)
+
+			dashboards.GET("/:id/shares", controllers.GetShareLinks)
+			dashboards.POST("/:id/shares", controllers.CreateShareLink)
 
 			dashboards.GET("/:id/widgets", controllers.GetWidgets)
 			dashboards.POST("/:id/widgets", controllers.CreateWidget)
+
+			dashboards.GET("/:id/revisions", controllers.GetDashboardRevisions)
+			dashboards.GET("/:id/revisions/diff", controllers.DiffDashboardRevisions)
+			dashboards.GET("/:id/revisions/:version", controllers.GetDashboardRevision)
+			dashboards.POST("/:id/revisions/:version/rollback", controllers.RollbackDashboard)
 		}
 
 		widgets := v1.Group("/widgets")
@@ -32,33 +100,99 @@ func SetupRoutes() *gin.Engine {
 			widgets.PUT("/:id", controllers.UpdateWidget)
 			widgets.PUT("/:id/state", controllers.UpdateWidgetState)
 			widgets.DELETE("/:id", controllers.DeleteWidget)
+			widgets.POST("/:id/clone", controllers.CloneWidget)
+			widgets.POST("/reorder", controllers.ReorderWidgets)
+
+			widgets.GET("/:id/revisions", controllers.GetWidgetRevisions)
+			widgets.GET("/:id/revisions/diff", controllers.DiffWidgetRevisions)
+			widgets.GET("/:id/revisions/:version", controllers.GetWidgetRevision)
+			widgets.POST("/:id/revisions/:version/rollback", controllers.RollbackWidget)
 		}
 
 		v1.GET("/adguard/:widget_id", controllers.ProxyAdGuardStats)
-		v1.POST("/adguard/test", controllers.TestAdGuardConnection)
+		v1.POST("/adguard/test", testLimit, controllers.TestAdGuardConnection)
 
 		v1.GET("/sonarr/:widget_id", controllers.ProxySonarrStats)
-		v1.POST("/sonarr/test", controllers.TestSonarrConnection)
+		v1.POST("/sonarr/test", testLimit, controllers.TestSonarrConnection)
 
 		v1.GET("/radarr/:widget_id", controllers.ProxyRadarrStats)
-		v1.POST("/radarr/test", controllers.TestRadarrConnection)
+		v1.POST("/radarr/test", testLimit, controllers.TestRadarrConnection)
 
 		v1.GET("/lidarr/:widget_id", controllers.ProxyLidarrStats)
-		v1.POST("/lidarr/test", controllers.TestLidarrConnection)
+		v1.POST("/lidarr/test", testLimit, controllers.TestLidarrConnection)
 
 		v1.GET("/transmission/:widget_id", controllers.ProxyTransmissionStats)
-		v1.POST("/transmission/test", controllers.TestTransmissionConnection)
+		v1.POST("/transmission/test", testLimit, controllers.TestTransmissionConnection)
 
 		v1.GET("/qbittorrent/:widget_id", controllers.ProxyQBittorrentStats)
-		v1.POST("/qbittorrent/test", controllers.TestQBittorrentConnection)
+		v1.POST("/qbittorrent/test", testLimit, controllers.TestQBittorrentConnection)
 
 		v1.GET("/immich/:widget_id", controllers.ProxyImmichStats)
-		v1.POST("/immich/test", controllers.TestImmichConnection)
+		v1.POST("/immich/test", testLimit, controllers.TestImmichConnection)
 
 		v1.GET("/prowlarr/:widget_id", controllers.ProxyProwlarrStats)
-		v1.POST("/prowlarr/test", controllers.TestProwlarrConnection)
+		v1.POST("/prowlarr/test", testLimit, controllers.TestProwlarrConnection)
+
+		v1.GET("/docker/:widget_id", controllers.ProxyDockerStats)
+		v1.POST("/docker/test", testLimit, controllers.TestDockerConnection)
+		v1.POST("/docker/:widget_id/containers/:container_id/:action", controllers.DockerContainerAction)
+
+		v1.GET("/kubernetes/:widget_id", controllers.ProxyKubernetesStats)
+		v1.POST("/kubernetes/test", testLimit, controllers.TestKubernetesConnection)
+
+		v1.GET("/proxmox/:widget_id", controllers.ProxyProxmoxStats)
+		v1.POST("/proxmox/test", testLimit, controllers.TestProxmoxConnection)
 
 		v1.GET("/system/stats", controllers.GetSystemStats)
+		v1.GET("/system/details", controllers.GetSystemDetails)
+
+		hosts := v1.Group("/system/hosts")
+		{
+			hosts.GET("", controllers.GetHosts)
+			hosts.POST("", controllers.CreateHost)
+			hosts.GET("/stats", controllers.GetAllHostStats)
+			hosts.POST("/test", testLimit, controllers.TestNewHost)
+			hosts.GET("/:id", controllers.GetHost)
+			hosts.PUT("/:id", controllers.UpdateHost)
+			hosts.DELETE("/:id", controllers.DeleteHost)
+			hosts.GET("/:id/stats", controllers.GetHostStats)
+			hosts.GET("/:id/details", controllers.GetHostDetails)
+			hosts.POST("/:id/test", testLimit, controllers.TestHostConnection)
+		}
+
+		v1.GET("/me", controllers.GetMe)
+		v1.PUT("/me/preferences", controllers.UpdatePreferences)
+
+		v1.GET("/audit", controllers.GetAuditLogs)
+		v1.DELETE("/shares/:id", controllers.RevokeShareLink)
+
+		trash := v1.Group("/trash")
+		{
+			trash.GET("", controllers.GetTrash)
+			trash.POST("/dashboards/:id/restore", controllers.RestoreDashboard)
+			trash.POST("/widgets/:id/restore", controllers.RestoreWidget)
+			trash.DELETE("/dashboards/:id", controllers.PurgeDashboard)
+			trash.DELETE("/widgets/:id", controllers.PurgeWidget)
+		}
+	}
+
+	// Share links are their own credential, so they bypass client
+	// certificate checks but keep the rate limits.
+	shared := r.Group(basePath+"/api/v1/share/:token",
+		middleware.Identity(trustedProxies),
+		middleware.RateLimit(security.NewLimiter(config.Int("RATE_LIMIT_PER_MINUTE", 600), config.Int("RATE_LIMIT_BURST", 100)), nil),
+		controllers.RequireShareToken(),
+	)
+	{
+		shared.GET("/dashboard", controllers.GetSharedDashboard)
+		shared.GET("/:integration/:widget_id", controllers.ProxySharedWidget)
+	}
+
+	if serveUI {
+		if !web.Available() {
+			slog.Warn("SERVE_UI is enabled but no UI bundle was embedded at build time")
+		}
+		r.NoRoute(web.Handler(basePath))
 	}
 
 	return r
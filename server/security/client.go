@@ -0,0 +1,16 @@
+package security
+
+import "context"
+
+type clientKey struct{}
+
+// WithClient tags ctx with the caller (user or client IP) so outbound
+// requests made on its behalf can be attributed for lockout purposes.
+func WithClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+func Client(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
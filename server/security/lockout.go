@@ -0,0 +1,130 @@
+package security
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"dashboard-server/config"
+)
+
+type LockedOutError struct {
+	Target     string
+	RetryAfter time.Duration
+}
+
+func (e *LockedOutError) Error() string {
+	return fmt.Sprintf("too many failed authentication attempts against %s, try again in %s", e.Target, e.RetryAfter.Round(time.Second))
+}
+
+type lockoutEntry struct {
+	failures    int
+	lastAttempt string
+	first       time.Time
+	lockedUntil time.Time
+}
+
+// Lockout counts authentication failures per key and blocks the key for a
+// while once too many happen within the window.
+type Lockout struct {
+	threshold int
+	window    time.Duration
+	duration  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*lockoutEntry
+}
+
+func NewLockout(threshold int, window, duration time.Duration) *Lockout {
+	return &Lockout{
+		threshold: threshold,
+		window:    window,
+		duration:  duration,
+		entries:   make(map[string]*lockoutEntry),
+	}
+}
+
+var (
+	defaultLockout     *Lockout
+	defaultLockoutOnce sync.Once
+)
+
+// Lockouts returns the process-wide tracker used for outbound integration
+// requests, configured from AUTH_LOCKOUT_* on first use.
+func Lockouts() *Lockout {
+	defaultLockoutOnce.Do(func() {
+		defaultLockout = NewLockout(
+			config.Int("AUTH_LOCKOUT_THRESHOLD", 5),
+			config.Duration("AUTH_LOCKOUT_WINDOW", 15*time.Minute),
+			config.Duration("AUTH_LOCKOUT_DURATION", 15*time.Minute),
+		)
+	})
+	return defaultLockout
+}
+
+// Check returns the remaining lock time for key, or zero when it may proceed.
+func (l *Lockout) Check(key string) time.Duration {
+	if l.threshold <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.entries[key]
+	if !ok {
+		return 0
+	}
+	if remaining := time.Until(entry.lockedUntil); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Failure records a failed attempt. A non-empty attempt ID (the request ID)
+// makes several upstream calls from one attempt count only once.
+func (l *Lockout) Failure(key, attempt string) {
+	if l.threshold <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.prune(now)
+
+	entry, ok := l.entries[key]
+	if !ok || now.Sub(entry.first) > l.window {
+		entry = &lockoutEntry{first: now}
+		l.entries[key] = entry
+	}
+
+	if attempt != "" && attempt == entry.lastAttempt {
+		return
+	}
+	entry.lastAttempt = attempt
+
+	entry.failures++
+	if entry.failures >= l.threshold {
+		entry.lockedUntil = now.Add(l.duration)
+		entry.failures = 0
+		entry.first = now
+	}
+}
+
+func (l *Lockout) Success(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if entry, ok := l.entries[key]; ok && time.Now().After(entry.lockedUntil) {
+		delete(l.entries, key)
+	}
+}
+
+func (l *Lockout) prune(now time.Time) {
+	for key, entry := range l.entries {
+		if now.Sub(entry.first) > l.window && now.After(entry.lockedUntil) {
+			delete(l.entries, key)
+		}
+	}
+}
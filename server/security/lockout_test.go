@@ -0,0 +1,89 @@
+package security
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLockoutAfterThreshold(t *testing.T) {
+	lockout := NewLockout(3, time.Minute, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		lockout.Failure("ip:1.2.3.4|sonarr", fmt.Sprint(i))
+	}
+	if remaining := lockout.Check("ip:1.2.3.4|sonarr"); remaining != 0 {
+		t.Fatalf("locked out after 2 of 3 failures (%s)", remaining)
+	}
+
+	lockout.Failure("ip:1.2.3.4|sonarr", "2")
+	remaining := lockout.Check("ip:1.2.3.4|sonarr")
+	if remaining <= 59*time.Minute || remaining > time.Hour {
+		t.Errorf("remaining = %s, want about an hour", remaining)
+	}
+
+	if remaining := lockout.Check("ip:5.6.7.8|sonarr"); remaining != 0 {
+		t.Error("another client was locked out")
+	}
+
+	lockout.Success("ip:1.2.3.4|sonarr")
+	if lockout.Check("ip:1.2.3.4|sonarr") == 0 {
+		t.Error("a success cleared an active lockout")
+	}
+}
+
+func TestLockoutCountsAttemptOnce(t *testing.T) {
+	lockout := NewLockout(2, time.Minute, time.Hour)
+
+	for i := 0; i < 5; i++ {
+		lockout.Failure("key", "request-1")
+	}
+	if lockout.Check("key") != 0 {
+		t.Fatal("repeated failures from one attempt caused a lockout")
+	}
+
+	lockout.Failure("key", "request-2")
+	if lockout.Check("key") == 0 {
+		t.Error("second attempt did not lock out")
+	}
+}
+
+func TestLockoutSuccessResets(t *testing.T) {
+	lockout := NewLockout(2, time.Minute, time.Hour)
+
+	lockout.Failure("key", "1")
+	lockout.Success("key")
+	lockout.Failure("key", "2")
+	if lockout.Check("key") != 0 {
+		t.Error("failures before a success still counted")
+	}
+}
+
+func TestLockoutWindowExpires(t *testing.T) {
+	lockout := NewLockout(2, time.Minute, time.Hour)
+
+	lockout.Failure("key", "1")
+	lockout.entries["key"].first = time.Now().Add(-2 * time.Minute)
+	lockout.Failure("key", "2")
+	if lockout.Check("key") != 0 {
+		t.Error("a failure outside the window counted towards the lockout")
+	}
+}
+
+func TestLockoutDisabled(t *testing.T) {
+	lockout := NewLockout(0, time.Minute, time.Hour)
+	for i := 0; i < 10; i++ {
+		lockout.Failure("key", fmt.Sprint(i))
+	}
+	if lockout.Check("key") != 0 {
+		t.Error("a zero threshold should disable lockouts")
+	}
+}
+
+func TestLockedOutError(t *testing.T) {
+	err := &LockedOutError{Target: "sonarr.lan", RetryAfter: 90*time.Second + 300*time.Millisecond}
+	want := "too many failed authentication attempts against sonarr.lan, try again in 1m30s"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,39 @@
+package security
+
+import (
+	"log/slog"
+	"net"
+	"strings"
+)
+
+// ParseNetworks turns IPs and CIDRs into networks, logging and skipping
+// entries that do not parse. Bare IPs become single-host networks.
+func ParseNetworks(entries []string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			slog.Warn("Ignoring invalid network", "value", entry, "error", err)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+func ContainsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package security
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterBurstAndKeys(t *testing.T) {
+	limiter := NewLimiter(60, 3)
+
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.Allow("a"); !allowed {
+			t.Fatalf("request %d within the burst was refused", i+1)
+		}
+	}
+
+	allowed, wait := limiter.Allow("a")
+	if allowed {
+		t.Fatal("request beyond the burst was allowed")
+	}
+	if wait <= 0 || wait > time.Second {
+		t.Errorf("wait = %s, want up to 1s at 60/min", wait)
+	}
+
+	if allowed, _ := limiter.Allow("b"); !allowed {
+		t.Error("a different key shares the exhausted bucket")
+	}
+}
+
+func TestLimiterRefills(t *testing.T) {
+	limiter := NewLimiter(60, 1)
+	limiter.Allow("a")
+	if allowed, _ := limiter.Allow("a"); allowed {
+		t.Fatal("empty bucket allowed a request")
+	}
+
+	limiter.buckets["a"].last = time.Now().Add(-1100 * time.Millisecond)
+	if allowed, _ := limiter.Allow("a"); !allowed {
+		t.Error("bucket did not refill after a second at 60/min")
+	}
+}
+
+func TestLimiterPrunesFullBuckets(t *testing.T) {
+	limiter := NewLimiter(60, 2)
+	limiter.Allow("old")
+	limiter.buckets["old"].last = time.Now().Add(-time.Hour)
+	limiter.lastPrune = time.Now().Add(-2 * time.Minute)
+
+	limiter.Allow("new")
+	if _, ok := limiter.buckets["old"]; ok {
+		t.Error("refilled bucket was not pruned")
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	limiter := NewLimiter(0, 10)
+	if limiter != nil {
+		t.Fatal("a zero rate should disable the limiter")
+	}
+	for i := 0; i < 1000; i++ {
+		if allowed, _ := limiter.Allow("a"); !allowed {
+			t.Fatal("nil limiter refused a request")
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package security
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"syscall"
+
+	"dashboard-server/config"
+)
+
+// TargetPolicy restricts which addresses integrations may connect to. An
+// empty allow list permits everything not explicitly denied.
+type TargetPolicy struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+func NewTargetPolicy(allow, deny []string) *TargetPolicy {
+	return &TargetPolicy{allow: ParseNetworks(allow), deny: ParseNetworks(deny)}
+}
+
+func (p *TargetPolicy) Permits(ip net.IP) bool {
+	if ContainsIP(p.deny, ip) {
+		return false
+	}
+	return len(p.allow) == 0 || ContainsIP(p.allow, ip)
+}
+
+// DialControl is a net.Dialer Control hook. It runs after DNS resolution, so
+// a hostname cannot be used to sneak past the policy.
+func (p *TargetPolicy) DialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !p.Permits(ip) {
+		return fmt.Errorf("connections to %s are not allowed by the outbound network policy", host)
+	}
+	return nil
+}
+
+var (
+	targetPolicy     *TargetPolicy
+	targetPolicyOnce sync.Once
+)
+
+// Targets returns the policy built from OUTBOUND_ALLOW_NETWORKS and
+// OUTBOUND_DENY_NETWORKS on first use.
+func Targets() *TargetPolicy {
+	targetPolicyOnce.Do(func() {
+		targetPolicy = NewTargetPolicy(
+			config.List("OUTBOUND_ALLOW_NETWORKS", nil),
+			config.List("OUTBOUND_DENY_NETWORKS", nil),
+		)
+	})
+	return targetPolicy
+}
@@ -0,0 +1,66 @@
+package security
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDialControl(t *testing.T) {
+	policy := NewTargetPolicy(
+		[]string{"10.0.0.0/8", "192.168.1.20", "fd00::/8"},
+		[]string{"10.0.0.1", "169.254.0.0/16"},
+	)
+
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"10.1.2.3:8989", true},
+		{"192.168.1.20:80", true},
+		{"[fd00::1]:443", true},
+		{"10.0.0.1:80", false},
+		{"192.168.1.21:80", false},
+		{"169.254.169.254:80", false},
+		{"8.8.8.8:53", false},
+		{"sonarr.lan:8989", false},
+		{"10.1.2.3", false},
+	}
+	for _, tt := range tests {
+		err := policy.DialControl("tcp", tt.address, nil)
+		if (err == nil) != tt.allowed {
+			t.Errorf("DialControl(%q) = %v, want allowed=%v", tt.address, err, tt.allowed)
+		}
+	}
+}
+
+func TestDialControlDenyOnly(t *testing.T) {
+	policy := NewTargetPolicy(nil, []string{"169.254.0.0/16"})
+
+	if err := policy.DialControl("tcp", "203.0.113.5:443", nil); err != nil {
+		t.Errorf("address outside the deny list was refused: %v", err)
+	}
+	if err := policy.DialControl("tcp", "169.254.169.254:80", nil); err == nil {
+		t.Error("denied address was allowed")
+	}
+}
+
+func TestDialControlBlocksConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	policy := NewTargetPolicy(nil, []string{"127.0.0.0/8", "::1"})
+	dialer := &net.Dialer{Timeout: time.Second, Control: policy.DialControl}
+	client := &http.Client{Transport: &http.Transport{DialContext: dialer.DialContext}}
+
+	// localhost resolves to a loopback address, which must be caught after
+	// resolution.
+	target := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	_, err := client.Get(target)
+	if err == nil || !strings.Contains(err.Error(), "outbound network policy") {
+		t.Errorf("request to a denied address: err = %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"dashboard-server/logging"
+)
+
+type ArrAPIVersion string
+
+const (
+	ArrAPIv1 ArrAPIVersion = "v1"
+	ArrAPIv3 ArrAPIVersion = "v3"
+)
+
+// arrAPIVersions lists the *arr applications the client knows about and the
+// API generation each of them speaks.
+var arrAPIVersions = map[string]ArrAPIVersion{
+	"sonarr":   ArrAPIv3,
+	"radarr":   ArrAPIv3,
+	"lidarr":   ArrAPIv1,
+	"prowlarr": ArrAPIv1,
+	"readarr":  ArrAPIv1,
+}
+
+// ArrClient talks to the Sonarr/Radarr/Lidarr/Prowlarr/Readarr family of
+// APIs. The API key is always sent in the X-Api-Key header, never in the URL.
+type ArrClient struct {
+	app     string
+	baseURL string
+	apiKey  string
+	version ArrAPIVersion
+	client  *http.Client
+}
+
+func NewArrClient(app, serverURL, apiKey string) (*ArrClient, error) {
+	version, ok := arrAPIVersions[app]
+	if !ok {
+		return nil, fmt.Errorf("unsupported *arr application %q", app)
+	}
+
+	parsed, err := url.Parse(strings.TrimSuffix(serverURL, "/"))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q", SanitizeURL(serverURL))
+	}
+
+	return &ArrClient{
+		app:     app,
+		baseURL: parsed.String(),
+		apiKey:  apiKey,
+		version: version,
+		client:  NewHTTPClient(15*time.Second, true),
+	}, nil
+}
+
+type ArrStatusError struct {
+	App        string
+	Endpoint   string
+	StatusCode int
+}
+
+func (e *ArrStatusError) Error() string {
+	return fmt.Sprintf("%s %s returned HTTP %d", e.App, e.Endpoint, e.StatusCode)
+}
+
+func IsArrStatusError(err error) bool {
+	var statusErr *ArrStatusError
+	return errors.As(err, &statusErr)
+}
+
+// Get requests /api/<version>/<endpoint> and decodes the JSON body into out.
+func (c *ArrClient) Get(ctx context.Context, endpoint string, query url.Values, out interface{}) error {
+	endpoint = strings.TrimPrefix(endpoint, "/")
+	requestURL := fmt.Sprintf("%s/api/%s/%s", c.baseURL, c.version, endpoint)
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %s", SanitizeURL(requestURL))
+	}
+
+	req.Header.Set("X-Api-Key", c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Homepage-Dashboard/1.0")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("connection to %s failed: %v", SanitizeURL(requestURL), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &ArrStatusError{App: c.app, Endpoint: endpoint, StatusCode: resp.StatusCode}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response from %s %s: %v", c.app, endpoint, err)
+	}
+
+	return nil
+}
+
+type ArrDiskSpace struct {
+	Path       string `json:"path"`
+	Label      string `json:"label"`
+	FreeSpace  int64  `json:"freeSpace"`
+	TotalSpace int64  `json:"totalSpace"`
+}
+
+type ArrRootFolder struct {
+	Path string `json:"path"`
+}
+
+// LibraryStorage adds up the disks that hold the application's root
+// folders. Each root folder lives on the disk with the longest mount path
+// containing it; a disk holding several root folders is counted once. When
+// the root folders cannot be read or match nothing, the first disk is used.
+func (c *ArrClient) LibraryStorage(ctx context.Context) (total, free int64, err error) {
+	var disks []ArrDiskSpace
+	if err := c.Get(ctx, "diskspace", nil, &disks); err != nil {
+		return 0, 0, err
+	}
+	if len(disks) == 0 {
+		return 0, 0, nil
+	}
+
+	var rootFolders []ArrRootFolder
+	if err := c.Get(ctx, "rootfolder", nil, &rootFolders); err != nil {
+		slog.DebugContext(ctx, "Failed to fetch root folders", "app", c.app, "error", err)
+	}
+
+	counted := make(map[int]bool)
+	for _, folder := range rootFolders {
+		match := -1
+		for i, disk := range disks {
+			if pathContains(disk.Path, folder.Path) && (match < 0 || len(disk.Path) > len(disks[match].Path)) {
+				match = i
+			}
+		}
+		if match >= 0 && !counted[match] {
+			counted[match] = true
+			total += disks[match].TotalSpace
+			free += disks[match].FreeSpace
+		}
+	}
+	if len(counted) == 0 {
+		return disks[0].TotalSpace, disks[0].FreeSpace, nil
+	}
+	return total, free, nil
+}
+
+// pathContains reports whether path is dir or lies below it. Both slash
+// styles are accepted since the application may run on Windows.
+func pathContains(dir, path string) bool {
+	dir = strings.TrimRight(dir, `/\`)
+	path = strings.TrimRight(path, `/\`)
+	if !strings.HasPrefix(path, dir) {
+		return false
+	}
+	return len(path) == len(dir) || path[len(dir)] == '/' || path[len(dir)] == '\\'
+}
+
+// SanitizeURL strips user info and sensitive query parameters so a URL can
+// be shown in errors and logs.
+func SanitizeURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return logging.Redact(rawURL)
+	}
+
+	parsed.User = nil
+	return logging.Redact(parsed.String())
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestArrLibraryStorage(t *testing.T) {
+	disks := []ArrDiskSpace{
+		{Path: "/", FreeSpace: 1, TotalSpace: 10},
+		{Path: "/data", FreeSpace: 20, TotalSpace: 200},
+		{Path: "/data/media", FreeSpace: 300, TotalSpace: 3000},
+		{Path: "/srv", FreeSpace: 4000, TotalSpace: 40000},
+	}
+
+	for _, tt := range []struct {
+		name        string
+		rootFolders []ArrRootFolder
+		total, free int64
+	}{
+		{"deepest mount wins", []ArrRootFolder{{Path: "/data/media/tv/"}}, 3000, 300},
+		{"disk counted once", []ArrRootFolder{{Path: "/data/media/tv"}, {Path: "/data/media/anime"}}, 3000, 300},
+		{"several disks", []ArrRootFolder{{Path: "/data/media/tv"}, {Path: "/srv/tv"}}, 43000, 4300},
+		{"prefix is not a parent", []ArrRootFolder{{Path: "/database/tv"}}, 10, 1},
+		{"no root folders", nil, 10, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-Api-Key") != "s3cret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				switch r.URL.Path {
+				case "/api/v3/diskspace":
+					json.NewEncoder(w).Encode(disks)
+				case "/api/v3/rootfolder":
+					json.NewEncoder(w).Encode(tt.rootFolders)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			client, err := NewArrClient("sonarr", server.URL, "s3cret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			total, free, err := client.LibraryStorage(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != tt.total || free != tt.free {
+				t.Errorf("storage = %d/%d, want %d/%d", free, total, tt.free, tt.total)
+			}
+		})
+	}
+}
+
+func TestPathContains(t *testing.T) {
+	for _, tt := range []struct {
+		dir, path string
+		want      bool
+	}{
+		{"/", "/tv", true},
+		{"/tv", "/tv/", true},
+		{"/data/", "/data/tv", true},
+		{"/data", "/database", false},
+		{`C:\`, `C:\TV`, true},
+		{`D:\`, `C:\TV`, false},
+	} {
+		if got := pathContains(tt.dir, tt.path); got != tt.want {
+			t.Errorf("pathContains(%q, %q) = %v, want %v", tt.dir, tt.path, got, tt.want)
+		}
+	}
+}
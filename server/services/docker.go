@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"dashboard-server/config"
+)
+
+// DefaultDockerEndpoint is the Docker Engine's local socket.
+const DefaultDockerEndpoint = "unix:///var/run/docker.sock"
+
+// DockerClient talks to the Docker Engine API over a unix socket or TCP.
+type DockerClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewDockerClient accepts unix:///path/to/docker.sock, tcp://host:port or an
+// http(s) URL. Unix sockets must be listed in DOCKER_SOCKETS, since the
+// socket grants full control of the host.
+func NewDockerClient(endpoint string, insecureSkipVerify bool) (*DockerClient, error) {
+	if endpoint == "" {
+		endpoint = DefaultDockerEndpoint
+	}
+
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Docker endpoint %q", SanitizeURL(endpoint))
+	}
+
+	switch parsed.Scheme {
+	case "unix":
+		socket := parsed.Path
+		if !dockerSocketAllowed(socket) {
+			return nil, fmt.Errorf("Docker socket %q is not listed in DOCKER_SOCKETS", socket)
+		}
+		return &DockerClient{
+			baseURL: "http://docker",
+			client:  &http.Client{Timeout: 15 * time.Second, Transport: dockerSocketTransport(socket)},
+		}, nil
+	case "tcp":
+		parsed.Scheme = "http"
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("unsupported Docker endpoint scheme %q", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("invalid Docker endpoint %q", SanitizeURL(endpoint))
+	}
+
+	return &DockerClient{
+		baseURL: strings.TrimSuffix(parsed.String(), "/"),
+		client:  NewHTTPClient(15*time.Second, insecureSkipVerify),
+	}, nil
+}
+
+// dockerSocketTransports holds one transport per socket path, so clients
+// created per request share idle connections instead of each leaving its
+// own open.
+var dockerSocketTransports sync.Map
+
+func dockerSocketTransport(socket string) http.RoundTripper {
+	if transport, ok := dockerSocketTransports.Load(socket); ok {
+		return transport.(http.RoundTripper)
+	}
+	transport, _ := dockerSocketTransports.LoadOrStore(socket, &loggingTransport{next: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+		IdleConnTimeout: 90 * time.Second,
+	}})
+	return transport.(http.RoundTripper)
+}
+
+func dockerSocketAllowed(socket string) bool {
+	for _, allowed := range config.List("DOCKER_SOCKETS", []string{"/var/run/docker.sock", "/run/docker.sock"}) {
+		if socket == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+type DockerContainer struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	State   string            `json:"State"`
+	Status  string            `json:"Status"`
+	Created int64             `json:"Created"`
+	Labels  map[string]string `json:"Labels"`
+}
+
+// Name is the container name without Docker's leading slash.
+func (c *DockerContainer) Name() string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+type DockerContainerInspect struct {
+	RestartCount int `json:"RestartCount"`
+	State        struct {
+		Status     string `json:"Status"`
+		Restarting bool   `json:"Restarting"`
+		ExitCode   int    `json:"ExitCode"`
+		StartedAt  string `json:"StartedAt"`
+		Health     *struct {
+			Status        string `json:"Status"`
+			FailingStreak int    `json:"FailingStreak"`
+		} `json:"Health"`
+	} `json:"State"`
+}
+
+type DockerContainerStats struct {
+	CPUStats    dockerCPUStats `json:"cpu_stats"`
+	PreCPUStats dockerCPUStats `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+}
+
+type dockerCPUStats struct {
+	CPUUsage struct {
+		TotalUsage  uint64   `json:"total_usage"`
+		PercpuUsage []uint64 `json:"percpu_usage"`
+	} `json:"cpu_usage"`
+	SystemCPUUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs     int    `json:"online_cpus"`
+}
+
+// CPUPercent follows the docker stats CLI: the container's share of all
+// CPUs between the two samples the engine returns, scaled by CPU count.
+func (s *DockerContainerStats) CPUPercent() float64 {
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemCPUUsage) - float64(s.PreCPUStats.SystemCPUUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	cpus := s.CPUStats.OnlineCPUs
+	if cpus == 0 {
+		cpus = len(s.CPUStats.CPUUsage.PercpuUsage)
+	}
+	if cpus == 0 {
+		cpus = 1
+	}
+	return cpuDelta / systemDelta * float64(cpus) * 100
+}
+
+// MemoryUsage excludes the page cache, as the docker stats CLI does.
+func (s *DockerContainerStats) MemoryUsage() uint64 {
+	cache := s.MemoryStats.Stats["inactive_file"]
+	if cache == 0 {
+		cache = s.MemoryStats.Stats["total_inactive_file"]
+	}
+	if cache > s.MemoryStats.Usage {
+		return s.MemoryStats.Usage
+	}
+	return s.MemoryStats.Usage - cache
+}
+
+func (c *DockerClient) do(ctx context.Context, method, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Docker: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 304 means the container was already in the requested state.
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.StatusCode >= 300 {
+		return dockerError(resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse Docker response: %w", err)
+	}
+	return nil
+}
+
+func dockerError(resp *http.Response) error {
+	var body struct {
+		Message string `json:"message"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&body)
+	if body.Message != "" {
+		return fmt.Errorf("Docker returned HTTP %d: %s", resp.StatusCode, body.Message)
+	}
+	return fmt.Errorf("Docker returned HTTP %d", resp.StatusCode)
+}
+
+func (c *DockerClient) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/_ping", nil)
+}
+
+func (c *DockerClient) ListContainers(ctx context.Context, all bool) ([]DockerContainer, error) {
+	var containers []DockerContainer
+	path := "/containers/json"
+	if all {
+		path += "?all=true"
+	}
+	if err := c.do(ctx, http.MethodGet, path, &containers); err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+func (c *DockerClient) InspectContainer(ctx context.Context, id string) (*DockerContainerInspect, error) {
+	var inspect DockerContainerInspect
+	if err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/json", &inspect); err != nil {
+		return nil, err
+	}
+	return &inspect, nil
+}
+
+// ContainerStats takes a single sample without streaming.
+func (c *DockerClient) ContainerStats(ctx context.Context, id string) (*DockerContainerStats, error) {
+	var stats DockerContainerStats
+	if err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/stats?stream=false", &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// ContainerAction starts, stops or restarts a container.
+func (c *DockerClient) ContainerAction(ctx context.Context, id, action string) error {
+	switch action {
+	case "start", "stop", "restart":
+	default:
+		return fmt.Errorf("unsupported container action %q", action)
+	}
+	return c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/"+action, nil)
+}
+
+type DockerEvent struct {
+	Type   string `json:"Type"`
+	Action string `json:"Action"`
+	Actor  struct {
+		ID         string            `json:"ID"`
+		Attributes map[string]string `json:"Attributes"`
+	} `json:"Actor"`
+}
+
+// Events streams container events to fn until ctx is cancelled or the
+// connection drops.
+func (c *DockerClient) Events(ctx context.Context, fn func(DockerEvent)) error {
+	filters := url.QueryEscape(`{"type":["container"]}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/events?filters="+filters, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	streaming := *c.client
+	streaming.Timeout = 0
+	resp, err := streaming.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Docker: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return dockerError(resp)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event DockerEvent
+		if err := decoder.Decode(&event); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("Docker event stream ended: %w", err)
+		}
+		fn(event)
+	}
+}
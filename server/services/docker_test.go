@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeDockerEngine answers the endpoints DockerClient uses for one running
+// container "web" and records container actions.
+func fakeDockerEngine(t *testing.T, actions *[]string) http.Handler {
+	t.Helper()
+	mux := http.NewServeMux()
+	reply := func(w http.ResponseWriter, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("GET /_ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	mux.HandleFunc("GET /containers/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("all") != "true" {
+			t.Errorf("list without all=true: %s", r.URL)
+		}
+		reply(w, []map[string]interface{}{
+			{"Id": "abc123", "Names": []string{"/web"}, "Image": "nginx", "State": "running", "Status": "Up 2 hours"},
+		})
+	})
+	mux.HandleFunc("GET /containers/abc123/json", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, map[string]interface{}{
+			"RestartCount": 2,
+			"State":        map[string]interface{}{"Status": "running", "Health": map[string]interface{}{"Status": "healthy"}},
+		})
+	})
+	mux.HandleFunc("GET /containers/abc123/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("stream") != "false" {
+			t.Errorf("stats without stream=false: %s", r.URL)
+		}
+		reply(w, map[string]interface{}{
+			"cpu_stats":    map[string]interface{}{"cpu_usage": map[string]interface{}{"total_usage": 300}, "system_cpu_usage": 2000, "online_cpus": 4},
+			"precpu_stats": map[string]interface{}{"cpu_usage": map[string]interface{}{"total_usage": 100}, "system_cpu_usage": 1000},
+			"memory_stats": map[string]interface{}{"usage": 500, "limit": 1000, "stats": map[string]uint64{"inactive_file": 100}},
+		})
+	})
+	mux.HandleFunc("POST /containers/abc123/{action}", func(w http.ResponseWriter, r *http.Request) {
+		*actions = append(*actions, r.PathValue("action"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		reply(w, map[string]string{"message": "No such container"})
+	})
+	return mux
+}
+
+func exerciseDockerClient(t *testing.T, client *DockerClient, actions *[]string) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	containers, err := client.ListContainers(ctx, true)
+	if err != nil {
+		t.Fatalf("ListContainers: %v", err)
+	}
+	if len(containers) != 1 || containers[0].Name() != "web" || containers[0].State != "running" {
+		t.Fatalf("containers = %+v", containers)
+	}
+
+	inspect, err := client.InspectContainer(ctx, "abc123")
+	if err != nil {
+		t.Fatalf("InspectContainer: %v", err)
+	}
+	if inspect.RestartCount != 2 || inspect.State.Health == nil || inspect.State.Health.Status != "healthy" {
+		t.Errorf("inspect = %+v", inspect)
+	}
+
+	stats, err := client.ContainerStats(ctx, "abc123")
+	if err != nil {
+		t.Fatalf("ContainerStats: %v", err)
+	}
+	if got := stats.CPUPercent(); math.Abs(got-80) > 1e-9 {
+		t.Errorf("CPUPercent = %v, want 80", got)
+	}
+	if got := stats.MemoryUsage(); got != 400 {
+		t.Errorf("MemoryUsage = %d, want 400", got)
+	}
+
+	if err := client.ContainerAction(ctx, "abc123", "restart"); err != nil {
+		t.Fatalf("ContainerAction: %v", err)
+	}
+	if len(*actions) != 1 || (*actions)[0] != "restart" {
+		t.Errorf("actions = %v", *actions)
+	}
+	if err := client.ContainerAction(ctx, "abc123", "kill"); err == nil {
+		t.Error("ContainerAction accepted kill")
+	}
+
+	_, err = client.InspectContainer(ctx, "missing")
+	if err == nil || !strings.Contains(err.Error(), "No such container") {
+		t.Errorf("InspectContainer(missing) error = %v", err)
+	}
+}
+
+func TestDockerClientTCP(t *testing.T) {
+	var actions []string
+	server := httptest.NewServer(fakeDockerEngine(t, &actions))
+	defer server.Close()
+
+	client, err := NewDockerClient("tcp://"+server.Listener.Addr().String(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exerciseDockerClient(t, client, &actions)
+}
+
+func TestDockerClientUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	var actions []string
+	server := httptest.NewUnstartedServer(fakeDockerEngine(t, &actions))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	t.Setenv("DOCKER_SOCKETS", socket)
+	client, err := NewDockerClient("unix://"+socket, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exerciseDockerClient(t, client, &actions)
+}
+
+func TestDockerClientsReuseSocketConnections(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	var actions []string
+	var opened atomic.Int32
+	server := httptest.NewUnstartedServer(fakeDockerEngine(t, &actions))
+	server.Listener = listener
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			opened.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	t.Setenv("DOCKER_SOCKETS", socket)
+	// Handlers create a client per request.
+	for i := 0; i < 50; i++ {
+		client, err := NewDockerClient("unix://"+socket, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := client.Ping(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := opened.Load(); n != 1 {
+		t.Errorf("50 clients opened %d connections, want 1", n)
+	}
+}
+
+func TestDockerSocketAllowList(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      *string
+		endpoint string
+		allowed  bool
+	}{
+		{"default socket", nil, "unix:///var/run/docker.sock", true},
+		{"empty endpoint uses the default", nil, "", true},
+		{"unlisted socket", nil, "unix:///tmp/docker.sock", false},
+		{"listed socket", ptr("/tmp/docker.sock, /srv/podman.sock"), "unix:///srv/podman.sock", true},
+		{"list replaces defaults", ptr("/srv/podman.sock"), "unix:///var/run/docker.sock", false},
+		{"empty list allows none", ptr(""), "unix:///var/run/docker.sock", false},
+		{"tcp is not affected", ptr(""), "tcp://docker.lan:2375", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != nil {
+				t.Setenv("DOCKER_SOCKETS", *tt.env)
+			}
+			_, err := NewDockerClient(tt.endpoint, false)
+			if tt.allowed && err != nil {
+				t.Errorf("NewDockerClient(%q) = %v", tt.endpoint, err)
+			}
+			if !tt.allowed && (err == nil || !strings.Contains(err.Error(), "DOCKER_SOCKETS")) {
+				t.Errorf("NewDockerClient(%q) error = %v, want allow-list error", tt.endpoint, err)
+			}
+		})
+	}
+}
+
+func TestCPUPercent(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats string
+		want  float64
+	}{
+		{
+			name:  "online cpus",
+			stats: `{"cpu_stats":{"cpu_usage":{"total_usage":300},"system_cpu_usage":2000,"online_cpus":2},"precpu_stats":{"cpu_usage":{"total_usage":100},"system_cpu_usage":1000}}`,
+			want:  40,
+		},
+		{
+			name:  "per-cpu usage on older engines",
+			stats: `{"cpu_stats":{"cpu_usage":{"total_usage":300,"percpu_usage":[1,2,3,4]},"system_cpu_usage":2000},"precpu_stats":{"cpu_usage":{"total_usage":100},"system_cpu_usage":1000}}`,
+			want:  80,
+		},
+		{
+			name:  "first sample has no previous reading",
+			stats: `{"cpu_stats":{"cpu_usage":{"total_usage":300},"system_cpu_usage":2000,"online_cpus":2},"precpu_stats":{}}`,
+			want:  30,
+		},
+		{
+			name:  "stopped container",
+			stats: `{"cpu_stats":{},"precpu_stats":{}}`,
+			want:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stats DockerContainerStats
+			if err := json.Unmarshal([]byte(tt.stats), &stats); err != nil {
+				t.Fatal(err)
+			}
+			if got := stats.CPUPercent(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("CPUPercent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"dashboard-server/logging"
+	"dashboard-server/security"
+)
+
+var (
+	defaultTransport  = newTransport(false)
+	insecureTransport = newTransport(true)
+)
+
+func newTransport(insecureSkipVerify bool) http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, conn syscall.RawConn) error {
+			return security.Targets().DialControl(network, address, conn)
+		},
+	}
+	transport.DialContext = dialer.DialContext
+	if insecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &loggingTransport{next: &lockoutTransport{next: transport}}
+}
+
+// NewHTTPClient returns a client for talking to integrations. Requests made
+// with a context carry its request ID upstream and are logged with
+// credentials redacted. Connections are subject to the outbound network
+// policy, and callers that keep failing authentication are locked out.
+func NewHTTPClient(timeout time.Duration, insecureSkipVerify bool) *http.Client {
+	transport := defaultTransport
+	if insecureSkipVerify {
+		transport = insecureTransport
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if requestID := logging.RequestID(ctx); requestID != "" && req.Header.Get("X-Request-ID") == "" {
+		req = req.Clone(ctx)
+		req.Header.Set("X-Request-ID", requestID)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	attrs := []any{
+		"method", req.Method,
+		"url", req.URL.String(),
+		"duration", time.Since(start),
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "Upstream request failed", append(attrs, "error", err)...)
+		return nil, err
+	}
+
+	slog.DebugContext(ctx, "Upstream request", append(attrs, "status", resp.StatusCode)...)
+	return resp, nil
+}
+
+// lockoutTransport counts 401/403 responses per caller and upstream host.
+// Requests without a caller, such as background refreshes, are not tracked.
+type lockoutTransport struct {
+	next http.RoundTripper
+}
+
+func (t *lockoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	client := security.Client(req.Context())
+	if client == "" {
+		return t.next.RoundTrip(req)
+	}
+
+	lockouts := security.Lockouts()
+	key := client + "|" + req.URL.Host
+	if remaining := lockouts.Check(key); remaining > 0 {
+		return nil, &security.LockedOutError{Target: req.URL.Host, RetryAfter: remaining}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		lockouts.Failure(key, logging.RequestID(req.Context()))
+		slog.WarnContext(req.Context(), "Upstream rejected credentials", "host", req.URL.Host, "status", resp.StatusCode)
+	case resp.StatusCode < 400:
+		lockouts.Success(key)
+	}
+	return resp, nil
+}
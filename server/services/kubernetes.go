@@ -0,0 +1,452 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"dashboard-server/config"
+
+	"github.com/goccy/go-yaml"
+)
+
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+var ErrNoKubernetesConfig = errors.New("no Kubernetes configuration found: set KUBECONFIG or run inside a cluster")
+
+// KubernetesClient reads from the Kubernetes API with the server's own
+// credentials, never with credentials from a widget.
+type KubernetesClient struct {
+	baseURL   string
+	token     string
+	tokenFile string
+	username  string
+	password  string
+	transport *http.Transport
+	client    *http.Client
+}
+
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string      `yaml:"token"`
+			TokenFile             string      `yaml:"tokenFile"`
+			ClientCertificate     string      `yaml:"client-certificate"`
+			ClientCertificateData string      `yaml:"client-certificate-data"`
+			ClientKey             string      `yaml:"client-key"`
+			ClientKeyData         string      `yaml:"client-key-data"`
+			Username              string      `yaml:"username"`
+			Password              string      `yaml:"password"`
+			Exec                  interface{} `yaml:"exec"`
+			AuthProvider          interface{} `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// NewKubernetesClient uses the first file in KUBECONFIG, the pod's service
+// account when running in a cluster, or ~/.kube/config, in that order.
+// kubeContext picks a kubeconfig context; empty means the current one.
+// Call Close when done.
+func NewKubernetesClient(kubeContext string) (*KubernetesClient, error) {
+	if paths := filepath.SplitList(config.String("KUBECONFIG", "")); len(paths) > 0 {
+		return kubernetesFromKubeconfig(paths[0], kubeContext)
+	}
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		if kubeContext != "" {
+			return nil, fmt.Errorf("context %q needs a kubeconfig, but the server uses its in-cluster service account", kubeContext)
+		}
+		return kubernetesInCluster()
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		path := filepath.Join(home, ".kube", "config")
+		if _, err := os.Stat(path); err == nil {
+			return kubernetesFromKubeconfig(path, kubeContext)
+		}
+	}
+	return nil, ErrNoKubernetesConfig
+}
+
+func kubernetesInCluster() (*KubernetesClient, error) {
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account CA: %w", err)
+	}
+	tlsConfig, err := kubernetesTLS(ca, false)
+	if err != nil {
+		return nil, err
+	}
+
+	host := net.JoinHostPort(os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT"))
+	return newKubernetesClient("https://"+host, tlsConfig, &KubernetesClient{
+		tokenFile: filepath.Join(serviceAccountDir, "token"),
+	}), nil
+}
+
+func kubernetesFromKubeconfig(path, kubeContext string) (*KubernetesClient, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	var cfg kubeconfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	if kubeContext == "" {
+		kubeContext = cfg.CurrentContext
+	}
+	var clusterName, userName string
+	found := false
+	for _, c := range cfg.Contexts {
+		if c.Name == kubeContext {
+			clusterName, userName, found = c.Context.Cluster, c.Context.User, true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", kubeContext)
+	}
+
+	dir := filepath.Dir(path)
+	resolve := func(file string) string {
+		if file == "" || filepath.IsAbs(file) {
+			return file
+		}
+		return filepath.Join(dir, file)
+	}
+
+	client := &KubernetesClient{}
+	var tlsConfig *tls.Config
+	found = false
+	for _, c := range cfg.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		ca, err := fileOrData(resolve(c.Cluster.CertificateAuthority), c.Cluster.CertificateAuthorityData)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %q: %w", clusterName, err)
+		}
+		tlsConfig, err = kubernetesTLS(ca, c.Cluster.InsecureSkipTLSVerify)
+		if err != nil {
+			return nil, err
+		}
+		client.baseURL = strings.TrimSuffix(c.Cluster.Server, "/")
+		found = true
+		break
+	}
+	if !found {
+		return nil, fmt.Errorf("cluster %q not found in kubeconfig", clusterName)
+	}
+	if _, err := url.Parse(client.baseURL); err != nil || client.baseURL == "" {
+		return nil, fmt.Errorf("cluster %q has an invalid server URL", clusterName)
+	}
+
+	for _, u := range cfg.Users {
+		if u.Name != userName {
+			continue
+		}
+		if u.User.Exec != nil || u.User.AuthProvider != nil {
+			return nil, fmt.Errorf("user %q uses an exec or auth-provider plugin, which is not supported; use a token or client certificate", userName)
+		}
+		client.token = u.User.Token
+		client.tokenFile = resolve(u.User.TokenFile)
+		client.username, client.password = u.User.Username, u.User.Password
+
+		certPEM, err := fileOrData(resolve(u.User.ClientCertificate), u.User.ClientCertificateData)
+		if err != nil {
+			return nil, fmt.Errorf("user %q: %w", userName, err)
+		}
+		keyPEM, err := fileOrData(resolve(u.User.ClientKey), u.User.ClientKeyData)
+		if err != nil {
+			return nil, fmt.Errorf("user %q: %w", userName, err)
+		}
+		if certPEM != nil && keyPEM != nil {
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			if err != nil {
+				return nil, fmt.Errorf("user %q has an invalid client certificate: %w", userName, err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		break
+	}
+
+	return newKubernetesClient(client.baseURL, tlsConfig, client), nil
+}
+
+// fileOrData returns base64 data from the kubeconfig or reads the file it
+// points to; kubeconfig allows either.
+func fileOrData(file, data string) ([]byte, error) {
+	if data != "" {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 data: %w", err)
+		}
+		return decoded, nil
+	}
+	if file == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return content, nil
+}
+
+func kubernetesTLS(ca []byte, insecureSkipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+	if ca != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("no certificates found in the cluster CA")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+func newKubernetesClient(baseURL string, tlsConfig *tls.Config, client *KubernetesClient) *KubernetesClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	client.baseURL = baseURL
+	client.transport = transport
+	client.client = &http.Client{Timeout: 15 * time.Second, Transport: &loggingTransport{next: transport}}
+	return client
+}
+
+// Close releases the client's idle connections.
+func (c *KubernetesClient) Close() {
+	c.transport.CloseIdleConnections()
+}
+
+func (c *KubernetesClient) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	token := c.token
+	if c.tokenFile != "" {
+		data, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	switch {
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Kubernetes API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var status struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&status)
+		if status.Message != "" {
+			return fmt.Errorf("Kubernetes API returned HTTP %d: %s", resp.StatusCode, status.Message)
+		}
+		return fmt.Errorf("Kubernetes API returned HTTP %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse Kubernetes response: %w", err)
+	}
+	return nil
+}
+
+type KubernetesMeta struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	CreationTimestamp time.Time         `json:"creationTimestamp"`
+	Labels            map[string]string `json:"labels"`
+	Annotations       map[string]string `json:"annotations"`
+}
+
+type KubernetesNode struct {
+	Metadata KubernetesMeta `json:"metadata"`
+	Spec     struct {
+		Unschedulable bool `json:"unschedulable"`
+	} `json:"spec"`
+	Status struct {
+		Conditions []struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+func (n *KubernetesNode) Ready() bool {
+	for _, condition := range n.Status.Conditions {
+		if condition.Type == "Ready" {
+			return condition.Status == "True"
+		}
+	}
+	return false
+}
+
+type KubernetesPod struct {
+	Metadata KubernetesMeta `json:"metadata"`
+	Status   struct {
+		Phase             string `json:"phase"`
+		ContainerStatuses []struct {
+			Name         string `json:"name"`
+			RestartCount int    `json:"restartCount"`
+			State        struct {
+				Waiting *struct {
+					Reason  string `json:"reason"`
+					Message string `json:"message"`
+				} `json:"waiting"`
+			} `json:"state"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type KubernetesPVC struct {
+	Metadata KubernetesMeta `json:"metadata"`
+	Spec     struct {
+		StorageClassName *string `json:"storageClassName"`
+	} `json:"spec"`
+	Status struct {
+		Phase string `json:"phase"`
+	} `json:"status"`
+}
+
+type KubernetesEvent struct {
+	Metadata       KubernetesMeta `json:"metadata"`
+	Type           string         `json:"type"`
+	Reason         string         `json:"reason"`
+	Message        string         `json:"message"`
+	Count          int            `json:"count"`
+	LastTimestamp  *time.Time     `json:"lastTimestamp"`
+	EventTime      *time.Time     `json:"eventTime"`
+	InvolvedObject struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	} `json:"involvedObject"`
+}
+
+// LastSeen falls back through the timestamps events may leave empty.
+func (e *KubernetesEvent) LastSeen() time.Time {
+	switch {
+	case e.LastTimestamp != nil:
+		return *e.LastTimestamp
+	case e.EventTime != nil:
+		return *e.EventTime
+	default:
+		return e.Metadata.CreationTimestamp
+	}
+}
+
+type KubernetesService struct {
+	Metadata KubernetesMeta `json:"metadata"`
+	Spec     struct {
+		Ports []struct {
+			Name string `json:"name"`
+			Port int    `json:"port"`
+		} `json:"ports"`
+	} `json:"spec"`
+}
+
+type KubernetesIngress struct {
+	Metadata KubernetesMeta `json:"metadata"`
+	Spec     struct {
+		TLS []struct {
+			Hosts []string `json:"hosts"`
+		} `json:"tls"`
+		Rules []struct {
+			Host string `json:"host"`
+		} `json:"rules"`
+	} `json:"spec"`
+}
+
+// listItems fetches a resource across all namespaces, or only the given
+// ones.
+func listItems[T any](ctx context.Context, c *KubernetesClient, group, resource string, namespaces []string, query url.Values) ([]T, error) {
+	paths := []string{group + "/" + resource}
+	if len(namespaces) > 0 {
+		paths = paths[:0]
+		for _, namespace := range namespaces {
+			paths = append(paths, group+"/namespaces/"+url.PathEscape(namespace)+"/"+resource)
+		}
+	}
+
+	var items []T
+	for _, path := range paths {
+		var list struct {
+			Items []T `json:"items"`
+		}
+		if err := c.get(ctx, path, query, &list); err != nil {
+			return nil, err
+		}
+		items = append(items, list.Items...)
+	}
+	return items, nil
+}
+
+func (c *KubernetesClient) Nodes(ctx context.Context) ([]KubernetesNode, error) {
+	return listItems[KubernetesNode](ctx, c, "/api/v1", "nodes", nil, nil)
+}
+
+func (c *KubernetesClient) Pods(ctx context.Context, namespaces []string) ([]KubernetesPod, error) {
+	return listItems[KubernetesPod](ctx, c, "/api/v1", "pods", namespaces, nil)
+}
+
+func (c *KubernetesClient) PersistentVolumeClaims(ctx context.Context, namespaces []string) ([]KubernetesPVC, error) {
+	return listItems[KubernetesPVC](ctx, c, "/api/v1", "persistentvolumeclaims", namespaces, nil)
+}
+
+func (c *KubernetesClient) WarningEvents(ctx context.Context, namespaces []string) ([]KubernetesEvent, error) {
+	return listItems[KubernetesEvent](ctx, c, "/api/v1", "events", namespaces, url.Values{"fieldSelector": {"type=Warning"}})
+}
+
+func (c *KubernetesClient) Services(ctx context.Context) ([]KubernetesService, error) {
+	return listItems[KubernetesService](ctx, c, "/api/v1", "services", nil, nil)
+}
+
+func (c *KubernetesClient) Ingresses(ctx context.Context) ([]KubernetesIngress, error) {
+	return listItems[KubernetesIngress](ctx, c, "/apis/networking.k8s.io/v1", "ingresses", nil, nil)
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ProxmoxClient talks to the Proxmox VE API with an API token. The token is
+// sent in the Authorization header as PVEAPIToken=<user>@<realm>!<name>=<secret>.
+type ProxmoxClient struct {
+	baseURL string
+	tokenID string
+	secret  string
+	client  *http.Client
+}
+
+func NewProxmoxClient(serverURL, tokenID, secret string, insecureSkipVerify bool) (*ProxmoxClient, error) {
+	parsed, err := url.Parse(strings.TrimSuffix(serverURL, "/"))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q", SanitizeURL(serverURL))
+	}
+	if !strings.Contains(tokenID, "!") {
+		return nil, fmt.Errorf("token ID must look like user@realm!name")
+	}
+
+	return &ProxmoxClient{
+		baseURL: strings.TrimSuffix(parsed.String(), "/api2/json"),
+		tokenID: tokenID,
+		secret:  secret,
+		client:  NewHTTPClient(15*time.Second, insecureSkipVerify),
+	}, nil
+}
+
+// Get requests /api2/json/<endpoint> and decodes the "data" field of the
+// response into out.
+func (c *ProxmoxClient) Get(ctx context.Context, endpoint string, query url.Values, out interface{}) error {
+	endpoint = strings.TrimPrefix(endpoint, "/")
+	target := c.baseURL + "/api2/json/" + endpoint
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("PVEAPIToken=%s=%s", c.tokenID, c.secret))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Proxmox: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		// Proxmox puts the reason in the status line, e.g.
+		// "401 authentication failure".
+		return fmt.Errorf("proxmox %s returned HTTP %s", endpoint, resp.Status)
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("failed to parse Proxmox response: %w", err)
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to parse Proxmox %s: %w", endpoint, err)
+	}
+	return nil
+}
+
+// ProxmoxResource is one entry of /cluster/resources. Which fields are set
+// depends on Type: node, qemu, lxc or storage.
+type ProxmoxResource struct {
+	ID         string  `json:"id"`
+	Type       string  `json:"type"`
+	Node       string  `json:"node"`
+	Name       string  `json:"name"`
+	Storage    string  `json:"storage"`
+	PluginType string  `json:"plugintype"`
+	Status     string  `json:"status"`
+	VMID       int     `json:"vmid"`
+	Template   int     `json:"template"`
+	Shared     int     `json:"shared"`
+	CPU        float64 `json:"cpu"`
+	MaxCPU     float64 `json:"maxcpu"`
+	Mem        int64   `json:"mem"`
+	MaxMem     int64   `json:"maxmem"`
+	Disk       int64   `json:"disk"`
+	MaxDisk    int64   `json:"maxdisk"`
+	Uptime     int64   `json:"uptime"`
+}
+
+// ProxmoxTask is one entry of /cluster/tasks. Status is "OK" for
+// successful tasks, "WARNINGS: n" for tasks that finished with warnings and
+// the error message otherwise; running tasks have none.
+type ProxmoxTask struct {
+	UPID      string `json:"upid"`
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	Node      string `json:"node"`
+	User      string `json:"user"`
+	Status    string `json:"status"`
+	StartTime int64  `json:"starttime"`
+	EndTime   int64  `json:"endtime"`
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var workers sync.WaitGroup
+
+// StartWorker runs fn in the background until ctx is cancelled. Shutdown
+// waits for every worker started this way via WaitForWorkers.
+func StartWorker(ctx context.Context, name string, fn func(ctx context.Context)) {
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		fn(ctx)
+		slog.Info("Background worker stopped", "worker", name)
+	}()
+}
+
+func WaitForWorkers(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
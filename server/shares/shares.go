@@ -0,0 +1,173 @@
+package shares
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"dashboard-server/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidToken = errors.New("share link is invalid, expired or revoked")
+
+const secretName = "share_signing_key"
+
+var (
+	signingKey   []byte
+	signingKeyMu sync.Mutex
+)
+
+// key returns SHARE_SECRET, or a random key generated once and kept in the
+// database so links survive restarts.
+func key(db *gorm.DB) ([]byte, error) {
+	signingKeyMu.Lock()
+	defer signingKeyMu.Unlock()
+
+	if signingKey != nil {
+		return signingKey, nil
+	}
+	if secret := os.Getenv("SHARE_SECRET"); secret != "" {
+		signingKey = []byte(secret)
+		return signingKey, nil
+	}
+
+	generated := make([]byte, 32)
+	if _, err := rand.Read(generated); err != nil {
+		return nil, err
+	}
+
+	candidate := models.AppSecret{Name: secretName, Value: hex.EncodeToString(generated)}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&candidate).Error; err != nil {
+		return nil, err
+	}
+
+	var stored models.AppSecret
+	if err := db.First(&stored, "name = ?", secretName).Error; err != nil {
+		return nil, err
+	}
+
+	decoded, err := hex.DecodeString(stored.Value)
+	if err != nil {
+		return nil, err
+	}
+	signingKey = decoded
+	return signingKey, nil
+}
+
+func NewNonce() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(nonce), nil
+}
+
+func sign(k []byte, payload string) string {
+	mac := hmac.New(sha256.New, k)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Token returns the URL-safe token for a link: "<id>.<nonce>.<signature>".
+func Token(db *gorm.DB, link *models.ShareLink) (string, error) {
+	k, err := key(db)
+	if err != nil {
+		return "", fmt.Errorf("failed to load share signing key: %w", err)
+	}
+	payload := fmt.Sprintf("%d.%s", link.ID, link.Nonce)
+	return payload + "." + sign(k, payload), nil
+}
+
+// Authorize verifies a token and returns its link if it is still active and
+// its dashboard still exists.
+func Authorize(db *gorm.DB, token string) (*models.ShareLink, error) {
+	k, err := key(db)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(sign(k, payload)), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+
+	id, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var link models.ShareLink
+	if err := db.First(&link, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	if !hmac.Equal([]byte(link.Nonce), []byte(parts[1])) || !link.Active(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+
+	var count int64
+	if err := db.Model(&models.Dashboard{}).Where("id = ?", link.DashboardID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	return &link, nil
+}
+
+// RecordUse counts a request made with the link.
+func RecordUse(db *gorm.DB, link *models.ShareLink, clientIP string) error {
+	return db.Model(link).UpdateColumns(map[string]interface{}{
+		"use_count":    gorm.Expr("use_count + 1"),
+		"last_used_at": time.Now(),
+		"last_used_ip": clientIP,
+	}).Error
+}
+
+// RemoveFields deletes dotted paths from decoded JSON. Arrays on the way
+// are descended into element by element.
+func RemoveFields(data interface{}, paths []string) {
+	for _, path := range paths {
+		removePath(data, strings.Split(path, "."))
+	}
+}
+
+func removePath(data interface{}, parts []string) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		if len(parts) == 1 {
+			delete(v, parts[0])
+			return
+		}
+		if next, ok := v[parts[0]]; ok {
+			removePath(next, parts[1:])
+		}
+	case models.JSON:
+		removePath(map[string]interface{}(v), parts)
+	case models.FilteredJSON:
+		removePath(map[string]interface{}(v), parts)
+	case []interface{}:
+		for _, item := range v {
+			removePath(item, parts)
+		}
+	}
+}
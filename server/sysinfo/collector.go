@@ -0,0 +1,122 @@
+package sysinfo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"dashboard-server/config"
+	"dashboard-server/glances"
+)
+
+// Point is one summary in the collector's history.
+type Point struct {
+	At time.Time `json:"at"`
+	glances.Stats
+}
+
+// Collector samples the local machine on an interval so handlers never
+// wait for a CPU sample. It keeps the latest details and a ring buffer of
+// summaries.
+type Collector struct {
+	interval time.Duration
+	sampler  sampler
+
+	mu       sync.RWMutex
+	latest   *glances.Details
+	latestAt time.Time
+	points   []Point
+	next     int
+	count    int
+}
+
+func NewCollector(interval time.Duration, history int) *Collector {
+	if history < 1 {
+		history = 1
+	}
+	return &Collector{interval: interval, points: make([]Point, history)}
+}
+
+var (
+	localCollector     *Collector
+	localCollectorOnce sync.Once
+)
+
+// Local returns the process-wide collector, configured from
+// SYSTEM_STATS_INTERVAL and SYSTEM_STATS_HISTORY on first use.
+func Local() *Collector {
+	localCollectorOnce.Do(func() {
+		interval := config.Duration("SYSTEM_STATS_INTERVAL", 5*time.Second)
+		history := config.Duration("SYSTEM_STATS_HISTORY", time.Hour)
+		size := 1
+		if interval > 0 {
+			size = int(history / interval)
+		}
+		localCollector = NewCollector(interval, size)
+	})
+	return localCollector
+}
+
+// Run samples until ctx is cancelled. It does nothing when the interval is
+// zero, leaving handlers to collect on demand.
+func (c *Collector) Run(ctx context.Context) {
+	if c.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.record(time.Now(), c.sampler.collect(ctx, 0))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Collector) record(at time.Time, details *glances.Details) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.latest = details
+	c.latestAt = at
+	c.points[c.next] = Point{At: at, Stats: *details.Summary()}
+	c.next = (c.next + 1) % len(c.points)
+	if c.count < len(c.points) {
+		c.count++
+	}
+}
+
+// Latest returns the newest sample and when it was taken, or nil before the
+// first sample.
+func (c *Collector) Latest() (*glances.Details, time.Time) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.latest, c.latestAt
+}
+
+// History returns the summaries taken within window of the newest one,
+// oldest first.
+func (c *Collector) History(window time.Duration) []Point {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	points := []Point{}
+	if c.count == 0 {
+		return points
+	}
+
+	since := c.latestAt.Add(-window)
+	start := (c.next - c.count + len(c.points)) % len(c.points)
+	for i := 0; i < c.count; i++ {
+		point := c.points[(start+i)%len(c.points)]
+		if !point.At.Before(since) {
+			points = append(points, point)
+		}
+	}
+	return points
+}
@@ -0,0 +1,281 @@
+// Package sysinfo collects metrics for the machine the server runs on, in
+// the same shape as Glances data so widgets treat both sources alike.
+package sysinfo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"dashboard-server/glances"
+	"dashboard-server/metrics"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
+	"github.com/shirou/gopsutil/v4/host"
+	"github.com/shirou/gopsutil/v4/load"
+	"github.com/shirou/gopsutil/v4/mem"
+	"github.com/shirou/gopsutil/v4/net"
+	"github.com/shirou/gopsutil/v4/process"
+	"github.com/shirou/gopsutil/v4/sensors"
+)
+
+// counters is the I/O totals from the previous collection, kept so rates
+// can be computed from the difference. The first collection reports zero
+// rates.
+type counters struct {
+	at      time.Time
+	disks   map[string]disk.IOCountersStat
+	network map[string]net.IOCountersStat
+}
+
+// sampler turns successive I/O counters into rates. Each caller that
+// collects on its own schedule needs its own sampler.
+type sampler struct {
+	mu       sync.Mutex
+	previous *counters
+}
+
+var direct sampler
+
+// Collect samples CPU usage over sampleTime and reads everything else.
+// Sections that cannot be read on this platform are left empty.
+func Collect(ctx context.Context, sampleTime time.Duration) *glances.Details {
+	return direct.collect(ctx, sampleTime)
+}
+
+// collect with a zero sampleTime measures CPU usage since the previous call.
+func (s *sampler) collect(ctx context.Context, sampleTime time.Duration) *glances.Details {
+	defer metrics.ObserveFetch("system", time.Now(), nil)
+
+	details := &glances.Details{
+		Filesystems: []glances.Filesystem{},
+		Disks:       []glances.DiskIO{},
+		Network:     []glances.NetworkIO{},
+		Sensors:     []glances.Sensor{},
+		Containers:  []glances.Container{},
+		RAID:        []glances.RAIDArray{},
+		SMART:       []glances.SMARTDisk{},
+		TopCPU:      []glances.Process{},
+		TopMemory:   []glances.Process{},
+	}
+
+	details.CPU.Cores = []float64{}
+	if cores, err := cpu.PercentWithContext(ctx, sampleTime, true); err == nil && len(cores) > 0 {
+		total := 0.0
+		for _, core := range cores {
+			total += core
+		}
+		details.CPU.Usage = total / float64(len(cores))
+		details.CPU.Cores = cores
+	}
+
+	if info, err := host.InfoWithContext(ctx); err == nil {
+		details.Host = glances.HostInfo{
+			Hostname:      info.Hostname,
+			OS:            info.OS,
+			Platform:      info.Platform,
+			Version:       info.PlatformVersion,
+			UptimeSeconds: info.Uptime,
+		}
+	}
+
+	if memory, err := mem.VirtualMemoryWithContext(ctx); err == nil {
+		details.Memory = glances.Usage{Used: float64(memory.Used), Total: float64(memory.Total), Percentage: memory.UsedPercent}
+	}
+	if swap, err := mem.SwapMemoryWithContext(ctx); err == nil {
+		details.Swap = glances.Usage{Used: float64(swap.Used), Total: float64(swap.Total), Percentage: swap.UsedPercent}
+	}
+
+	if avg, err := load.AvgWithContext(ctx); err == nil {
+		details.Load = glances.Load{Min1: avg.Load1, Min5: avg.Load5, Min15: avg.Load15}
+	}
+
+	details.Processes = processCount(ctx)
+	details.Filesystems = filesystems(ctx)
+	details.Sensors, details.CPU.Temperature = temperatures(ctx)
+
+	now := time.Now()
+	current := &counters{at: now}
+	current.disks, _ = disk.IOCountersWithContext(ctx)
+	if interfaces, err := net.IOCountersWithContext(ctx, true); err == nil {
+		current.network = make(map[string]net.IOCountersStat, len(interfaces))
+		for _, iface := range interfaces {
+			current.network[iface.Name] = iface
+		}
+	}
+
+	s.mu.Lock()
+	last := s.previous
+	s.previous = current
+	s.mu.Unlock()
+
+	seconds := 0.0
+	if last != nil {
+		seconds = now.Sub(last.at).Seconds()
+	}
+
+	for _, name := range sortedNames(current.disks) {
+		counter := current.disks[name]
+		io := glances.DiskIO{Name: name, ReadBytes: float64(counter.ReadBytes), WriteBytes: float64(counter.WriteBytes)}
+		if old, ok := lastDisk(last, name); ok && seconds > 0 {
+			io.ReadRate = delta(counter.ReadBytes, old.ReadBytes) / seconds
+			io.WriteRate = delta(counter.WriteBytes, old.WriteBytes) / seconds
+		}
+		details.Disks = append(details.Disks, io)
+	}
+
+	for _, name := range sortedNames(current.network) {
+		counter := current.network[name]
+		if name == "lo" {
+			continue
+		}
+		io := glances.NetworkIO{Interface: name, RxBytes: float64(counter.BytesRecv), TxBytes: float64(counter.BytesSent)}
+		if old, ok := lastNetwork(last, name); ok && seconds > 0 {
+			io.RxRate = delta(counter.BytesRecv, old.BytesRecv) / seconds
+			io.TxRate = delta(counter.BytesSent, old.BytesSent) / seconds
+		}
+		details.Network = append(details.Network, io)
+	}
+
+	return details
+}
+
+func processCount(ctx context.Context) glances.ProcessCount {
+	count := glances.ProcessCount{}
+
+	processes, err := process.ProcessesWithContext(ctx)
+	if err != nil {
+		return count
+	}
+
+	for _, p := range processes {
+		status, err := p.StatusWithContext(ctx)
+		if err != nil {
+			// The process exited while we were looking.
+			continue
+		}
+		count.Total++
+		if len(status) > 0 {
+			switch status[0] {
+			case process.Running:
+				count.Running++
+			case process.Sleep, process.Idle:
+				count.Sleeping++
+			}
+		}
+		if threads, err := p.NumThreadsWithContext(ctx); err == nil {
+			count.Threads += int(threads)
+		}
+	}
+
+	return count
+}
+
+func filesystems(ctx context.Context) []glances.Filesystem {
+	result := []glances.Filesystem{}
+
+	partitions, err := disk.PartitionsWithContext(ctx, false)
+	if err != nil {
+		return result
+	}
+
+	for _, partition := range partitions {
+		usage, err := disk.UsageWithContext(ctx, partition.Mountpoint)
+		if err != nil || usage.Total == 0 {
+			continue
+		}
+		result = append(result, glances.Filesystem{
+			Mount:      partition.Mountpoint,
+			Device:     partition.Device,
+			Type:       partition.Fstype,
+			Used:       float64(usage.Used),
+			Free:       float64(usage.Free),
+			Total:      float64(usage.Total),
+			Percentage: usage.UsedPercent,
+		})
+	}
+
+	return result
+}
+
+// cpuSensors are sensor keys known to report the CPU package or first core.
+var cpuSensors = []string{
+	"coretemp_package_id_0_input",
+	"coretemp_core_0_input",
+	"k10temp_tctl",
+	"cpu_thermal",
+	"Package id 0",
+	"acpi_0",
+	"thermal_zone0",
+}
+
+// temperatures returns every temperature sensor and the best guess at the
+// CPU temperature. Readings outside 0-150°C are treated as bogus.
+func temperatures(ctx context.Context) ([]glances.Sensor, float64) {
+	result := []glances.Sensor{}
+
+	temps, err := sensors.TemperaturesWithContext(ctx)
+	if len(temps) == 0 && err != nil {
+		return result, 0
+	}
+
+	readings := make(map[string]float64)
+	first := 0.0
+	for _, temp := range temps {
+		if temp.Temperature <= 0 || temp.Temperature >= 150 {
+			continue
+		}
+		result = append(result, glances.Sensor{
+			Label: temp.SensorKey,
+			Type:  "temperature_core",
+			Value: temp.Temperature,
+			Unit:  "C",
+		})
+		readings[temp.SensorKey] = temp.Temperature
+		if first == 0 {
+			first = temp.Temperature
+		}
+	}
+
+	for _, key := range cpuSensors {
+		if temperature, ok := readings[key]; ok {
+			return result, temperature
+		}
+	}
+	return result, first
+}
+
+func lastDisk(last *counters, name string) (disk.IOCountersStat, bool) {
+	if last == nil {
+		return disk.IOCountersStat{}, false
+	}
+	counter, ok := last.disks[name]
+	return counter, ok
+}
+
+func lastNetwork(last *counters, name string) (net.IOCountersStat, bool) {
+	if last == nil {
+		return net.IOCountersStat{}, false
+	}
+	counter, ok := last.network[name]
+	return counter, ok
+}
+
+// delta treats a counter that went backwards (a reset or wrap) as no traffic.
+func delta(current, previous uint64) float64 {
+	if current < previous {
+		return 0
+	}
+	return float64(current - previous)
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
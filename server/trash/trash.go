@@ -0,0 +1,336 @@
+package trash
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"dashboard-server/audit"
+	"dashboard-server/config"
+	"dashboard-server/layout"
+	"dashboard-server/models"
+	"dashboard-server/revisions"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotInTrash       = errors.New("item is not in the trash")
+	ErrDashboardDeleted = errors.New("the widget's dashboard is in the trash, restore the dashboard instead")
+)
+
+// Item is one entry in the trash listing. Widgets deleted together with
+// their dashboard are folded into the dashboard's entry.
+type Item struct {
+	Type             string     `json:"type"`
+	ID               uint       `json:"id"`
+	Name             string     `json:"name"`
+	DashboardID      uint       `json:"dashboard_id,omitempty"`
+	DashboardDeleted bool       `json:"dashboard_deleted,omitempty"`
+	WidgetCount      int        `json:"widget_count,omitempty"`
+	DeletedAt        time.Time  `json:"deleted_at"`
+	PurgeAt          *time.Time `json:"purge_at,omitempty"`
+}
+
+// Retention is how long deleted items stay recoverable. Zero keeps them
+// until purged by hand.
+func Retention() time.Duration {
+	return config.Duration("TRASH_RETENTION", 30*24*time.Hour)
+}
+
+func purgeAt(deletedAt time.Time) *time.Time {
+	retention := Retention()
+	if retention <= 0 {
+		return nil
+	}
+	t := deletedAt.Add(retention)
+	return &t
+}
+
+func List(db *gorm.DB) ([]Item, error) {
+	var dashboards []models.Dashboard
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&dashboards).Error; err != nil {
+		return nil, err
+	}
+
+	var widgets []models.Widget
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&widgets).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(dashboards)+len(widgets))
+	index := make(map[uint]int, len(dashboards))
+	for _, d := range dashboards {
+		index[d.ID] = len(items)
+		items = append(items, Item{
+			Type:      audit.EntityDashboard,
+			ID:        d.ID,
+			Name:      d.Name,
+			DeletedAt: d.DeletedAt.Time,
+			PurgeAt:   purgeAt(d.DeletedAt.Time),
+		})
+	}
+
+	for _, w := range widgets {
+		i, dashboardDeleted := index[w.DashboardID]
+		if dashboardDeleted && items[i].DeletedAt.Equal(w.DeletedAt.Time) {
+			items[i].WidgetCount++
+			continue
+		}
+		items = append(items, Item{
+			Type:             audit.EntityWidget,
+			ID:               w.ID,
+			Name:             w.Name,
+			DashboardID:      w.DashboardID,
+			DashboardDeleted: dashboardDeleted,
+			DeletedAt:        w.DeletedAt.Time,
+			PurgeAt:          purgeAt(w.DeletedAt.Time),
+		})
+	}
+
+	return items, nil
+}
+
+// DeleteDashboard soft-deletes a dashboard and its widgets with one shared
+// timestamp, which is how RestoreDashboard finds them again.
+func DeleteDashboard(tx *gorm.DB, c *gin.Context, dashboard *models.Dashboard) error {
+	var widgets []models.Widget
+	if err := tx.Where("dashboard_id = ?", dashboard.ID).Find(&widgets).Error; err != nil {
+		return err
+	}
+
+	deletedAt := time.Now()
+	if err := tx.Model(&models.Widget{}).Where("dashboard_id = ?", dashboard.ID).Update("deleted_at", deletedAt).Error; err != nil {
+		return err
+	}
+	for i := range widgets {
+		if err := audit.Record(tx, c, models.AuditActionDelete, audit.EntityWidget, widgets[i].ID, audit.WidgetFields(&widgets[i]), nil); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(dashboard).Update("deleted_at", deletedAt).Error; err != nil {
+		return err
+	}
+	return audit.Record(tx, c, models.AuditActionDelete, audit.EntityDashboard, dashboard.ID, audit.DashboardFields(dashboard), nil)
+}
+
+// RestoreDashboard brings back a dashboard and the widgets deleted with it.
+// Widgets deleted individually beforehand stay in the trash.
+func RestoreDashboard(tx *gorm.DB, c *gin.Context, id uint) (*models.Dashboard, error) {
+	var dashboard models.Dashboard
+	if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&dashboard, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotInTrash
+		}
+		return nil, err
+	}
+
+	var widgets []models.Widget
+	if err := tx.Unscoped().Where("dashboard_id = ? AND deleted_at = ?", dashboard.ID, dashboard.DeletedAt.Time).Find(&widgets).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range widgets {
+		if err := tx.Unscoped().Model(&widgets[i]).Update("deleted_at", nil).Error; err != nil {
+			return nil, err
+		}
+		if err := audit.Record(tx, c, models.AuditActionRestore, audit.EntityWidget, widgets[i].ID, nil, audit.WidgetFields(&widgets[i])); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Unscoped().Model(&dashboard).Update("deleted_at", nil).Error; err != nil {
+		return nil, err
+	}
+	if err := audit.Record(tx, c, models.AuditActionRestore, audit.EntityDashboard, dashboard.ID, nil, audit.DashboardFields(&dashboard)); err != nil {
+		return nil, err
+	}
+	if err := revisions.SaveDashboard(tx, c, dashboard.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Preload("Widgets").First(&dashboard, dashboard.ID).Error; err != nil {
+		return nil, err
+	}
+	return &dashboard, nil
+}
+
+func RestoreWidget(tx *gorm.DB, c *gin.Context, id uint) (*models.Widget, error) {
+	widget, err := findDeletedWidget(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var dashboard models.Dashboard
+	if err := tx.First(&dashboard, widget.DashboardID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDashboardDeleted
+		}
+		return nil, err
+	}
+
+	// The grid may have filled up while the widget was in the trash.
+	if err := layout.PlaceInDashboard(tx, widget); err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Model(widget).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"section_id": widget.SectionID,
+		"layout":     widget.Layout,
+	}).Error; err != nil {
+		return nil, err
+	}
+	widget.DeletedAt = gorm.DeletedAt{}
+	if err := audit.Record(tx, c, models.AuditActionRestore, audit.EntityWidget, widget.ID, nil, audit.WidgetFields(widget)); err != nil {
+		return nil, err
+	}
+	if err := revisions.SaveWidget(tx, c, widget); err != nil {
+		return nil, err
+	}
+	return widget, nil
+}
+
+func findDeletedWidget(tx *gorm.DB, id uint) (*models.Widget, error) {
+	var widget models.Widget
+	if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&widget, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotInTrash
+		}
+		return nil, err
+	}
+	return &widget, nil
+}
+
+// recordFunc writes the audit entry for a purge, either for a user request
+// or for the background job.
+type recordFunc func(tx *gorm.DB, entityType string, entityID uint, before map[string]interface{}) error
+
+func userRecorder(c *gin.Context) recordFunc {
+	return func(tx *gorm.DB, entityType string, entityID uint, before map[string]interface{}) error {
+		return audit.Record(tx, c, models.AuditActionPurge, entityType, entityID, before, nil)
+	}
+}
+
+func systemRecorder(tx *gorm.DB, entityType string, entityID uint, before map[string]interface{}) error {
+	return audit.RecordSystem(tx, models.AuditActionPurge, entityType, entityID, before, nil)
+}
+
+// PurgeDashboard permanently removes a trashed dashboard, all of its
+// widgets and their revision history.
+func PurgeDashboard(tx *gorm.DB, c *gin.Context, id uint) error {
+	var dashboard models.Dashboard
+	if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&dashboard, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotInTrash
+		}
+		return err
+	}
+	return purgeDashboard(tx, &dashboard, userRecorder(c))
+}
+
+func PurgeWidget(tx *gorm.DB, c *gin.Context, id uint) error {
+	widget, err := findDeletedWidget(tx, id)
+	if err != nil {
+		return err
+	}
+	return purgeWidget(tx, widget, userRecorder(c))
+}
+
+func purgeDashboard(tx *gorm.DB, dashboard *models.Dashboard, record recordFunc) error {
+	var widgets []models.Widget
+	if err := tx.Unscoped().Where("dashboard_id = ?", dashboard.ID).Find(&widgets).Error; err != nil {
+		return err
+	}
+	for i := range widgets {
+		if err := purgeWidget(tx, &widgets[i], record); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Where("dashboard_id = ?", dashboard.ID).Delete(&models.Section{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Delete(dashboard).Error; err != nil {
+		return err
+	}
+	if err := deleteRevisions(tx, audit.EntityDashboard, dashboard.ID); err != nil {
+		return err
+	}
+	return record(tx, audit.EntityDashboard, dashboard.ID, audit.DashboardFields(dashboard))
+}
+
+func purgeWidget(tx *gorm.DB, widget *models.Widget, record recordFunc) error {
+	if err := tx.Unscoped().Delete(widget).Error; err != nil {
+		return err
+	}
+	if err := deleteRevisions(tx, audit.EntityWidget, widget.ID); err != nil {
+		return err
+	}
+	return record(tx, audit.EntityWidget, widget.ID, audit.WidgetFields(widget))
+}
+
+// deleteRevisions drops history along with the item; snapshots hold
+// credentials that should not outlive it.
+func deleteRevisions(tx *gorm.DB, entityType string, entityID uint) error {
+	return tx.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Delete(&models.Revision{}).Error
+}
+
+// PurgeExpired permanently removes everything deleted before cutoff.
+func PurgeExpired(db *gorm.DB, cutoff time.Time) (int, error) {
+	purged := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var dashboards []models.Dashboard
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Find(&dashboards).Error; err != nil {
+			return err
+		}
+		for i := range dashboards {
+			if err := purgeDashboard(tx, &dashboards[i], systemRecorder); err != nil {
+				return err
+			}
+			purged++
+		}
+
+		var widgets []models.Widget
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Find(&widgets).Error; err != nil {
+			return err
+		}
+		for i := range widgets {
+			if err := purgeWidget(tx, &widgets[i], systemRecorder); err != nil {
+				return err
+			}
+			purged++
+		}
+		return nil
+	})
+	return purged, err
+}
+
+// RunPurger removes expired trash every TRASH_PURGE_INTERVAL until ctx is
+// cancelled. It does nothing when TRASH_RETENTION is zero.
+func RunPurger(ctx context.Context, db *gorm.DB) {
+	retention := Retention()
+	if retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(config.Duration("TRASH_PURGE_INTERVAL", time.Hour))
+	defer ticker.Stop()
+
+	for {
+		purged, err := PurgeExpired(db, time.Now().Add(-retention))
+		if err != nil {
+			slog.Error("Failed to purge trash", "error", err)
+		} else if purged > 0 {
+			slog.Info("Purged expired trash", "items", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package trash
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dashboard-server/audit"
+	"dashboard-server/database"
+	"dashboard-server/models"
+	"dashboard-server/revisions"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func setup(t *testing.T) (*gorm.DB, *gin.Context) {
+	t.Helper()
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	return db, c
+}
+
+func createDashboard(t *testing.T, db *gorm.DB, name string, widgets ...string) (models.Dashboard, []models.Widget) {
+	t.Helper()
+	dashboard := models.Dashboard{Name: name}
+	if err := db.Omit("Sections").Create(&dashboard).Error; err != nil {
+		t.Fatal(err)
+	}
+	created := make([]models.Widget, 0, len(widgets))
+	for i, widgetName := range widgets {
+		widget := models.Widget{DashboardID: dashboard.ID, Name: widgetName, Type: "bookmark", Position: i, IsEnabled: true}
+		if err := db.Create(&widget).Error; err != nil {
+			t.Fatal(err)
+		}
+		created = append(created, widget)
+	}
+	return dashboard, created
+}
+
+func isDeleted(t *testing.T, db *gorm.DB, model interface{}, id uint) bool {
+	t.Helper()
+	var count int64
+	if err := db.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", id).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
+
+func TestRestoreDashboardLeavesEarlierDeletions(t *testing.T) {
+	db, c := setup(t)
+	dashboard, widgets := createDashboard(t, db, "Home", "Sonarr", "Radarr")
+	sonarr, radarr := widgets[0], widgets[1]
+
+	if err := db.Model(&radarr).Update("deleted_at", time.Now().Add(-time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteDashboard(db, c, &dashboard); err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := RestoreDashboard(db, c, dashboard.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.Widgets) != 1 || restored.Widgets[0].ID != sonarr.ID {
+		t.Errorf("restored widgets = %+v, want only Sonarr", restored.Widgets)
+	}
+	if isDeleted(t, db, &models.Dashboard{}, dashboard.ID) {
+		t.Error("dashboard is still in the trash")
+	}
+	if isDeleted(t, db, &models.Widget{}, sonarr.ID) {
+		t.Error("widget deleted with the dashboard is still in the trash")
+	}
+	if !isDeleted(t, db, &models.Widget{}, radarr.ID) {
+		t.Error("widget deleted before the dashboard was restored with it")
+	}
+
+	if _, err := RestoreDashboard(db, c, dashboard.ID); !errors.Is(err, ErrNotInTrash) {
+		t.Errorf("second restore: err = %v, want ErrNotInTrash", err)
+	}
+}
+
+func TestRestoreWidgetOfDeletedDashboard(t *testing.T) {
+	db, c := setup(t)
+	dashboard, widgets := createDashboard(t, db, "Home", "Sonarr")
+	if err := DeleteDashboard(db, c, &dashboard); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := RestoreWidget(db, c, widgets[0].ID); !errors.Is(err, ErrDashboardDeleted) {
+		t.Fatalf("err = %v, want ErrDashboardDeleted", err)
+	}
+	if !isDeleted(t, db, &models.Widget{}, widgets[0].ID) {
+		t.Error("widget left the trash without its dashboard")
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	db, c := setup(t)
+	expired, expiredWidgets := createDashboard(t, db, "Old", "Sonarr")
+	recent, recentWidgets := createDashboard(t, db, "New", "Radarr", "Lidarr")
+	for _, id := range []uint{expired.ID, recent.ID} {
+		if err := revisions.SaveDashboard(db, c, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range recentWidgets {
+		if err := revisions.SaveWidget(db, c, &recentWidgets[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := DeleteDashboard(db, c, &expired); err != nil {
+		t.Fatal(err)
+	}
+	twoDaysAgo := time.Now().Add(-48 * time.Hour)
+	if err := db.Unscoped().Model(&models.Dashboard{}).Where("id = ?", expired.ID).Update("deleted_at", twoDaysAgo).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Unscoped().Model(&models.Widget{}).Where("dashboard_id = ?", expired.ID).Update("deleted_at", twoDaysAgo).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&recentWidgets[0]).Update("deleted_at", twoDaysAgo).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&recentWidgets[1]).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := PurgeExpired(db, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 2 {
+		t.Errorf("purged %d items, want 2 (the old dashboard and Radarr)", purged)
+	}
+
+	exists := func(model interface{}, id uint) bool {
+		t.Helper()
+		var count int64
+		if err := db.Unscoped().Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		return count > 0
+	}
+	revisionCount := func(entityType string, id uint) int64 {
+		t.Helper()
+		var count int64
+		if err := db.Model(&models.Revision{}).Where("entity_type = ? AND entity_id = ?", entityType, id).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	if exists(&models.Dashboard{}, expired.ID) || exists(&models.Widget{}, expiredWidgets[0].ID) {
+		t.Error("expired dashboard or its widget survived the purge")
+	}
+	if exists(&models.Widget{}, recentWidgets[0].ID) {
+		t.Error("expired widget survived the purge")
+	}
+	if !exists(&models.Dashboard{}, recent.ID) || !exists(&models.Widget{}, recentWidgets[1].ID) {
+		t.Error("items deleted after the cutoff were purged")
+	}
+
+	if n := revisionCount(audit.EntityDashboard, expired.ID); n != 0 {
+		t.Errorf("%d revisions left for the purged dashboard", n)
+	}
+	if n := revisionCount(audit.EntityWidget, recentWidgets[0].ID); n != 0 {
+		t.Errorf("%d revisions left for the purged widget", n)
+	}
+	if n := revisionCount(audit.EntityDashboard, recent.ID); n == 0 {
+		t.Error("revisions of a live dashboard were deleted")
+	}
+	if n := revisionCount(audit.EntityWidget, recentWidgets[1].ID); n == 0 {
+		t.Error("revisions of a widget deleted after the cutoff were deleted")
+	}
+}
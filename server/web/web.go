@@ -0,0 +1,93 @@
+package web
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dist holds the production build of the Svelte app. It is populated by
+// copying the Vite output into web/dist before `go build`.
+//
+//go:embed all:dist
+var dist embed.FS
+
+func assets() fs.FS {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// Available reports whether a UI bundle was embedded into this binary.
+func Available() bool {
+	_, err := fs.Stat(assets(), "index.html")
+	return err == nil
+}
+
+// Handler serves the embedded UI under basePath. Unknown paths fall back to
+// index.html so client-side routes survive a page reload; API paths are
+// never rewritten.
+func Handler(basePath string) gin.HandlerFunc {
+	files := assets()
+	fileServer := http.StripPrefix(basePath, http.FileServer(http.FS(files)))
+	index := indexWithBase(files, basePath)
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		urlPath := c.Request.URL.Path
+		if basePath != "" && urlPath == basePath {
+			c.Redirect(http.StatusMovedPermanently, basePath+"/")
+			return
+		}
+		if !strings.HasPrefix(urlPath, basePath+"/") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		requestPath := strings.TrimPrefix(path.Clean(strings.TrimPrefix(urlPath, basePath)), "/")
+		if requestPath == "api" || strings.HasPrefix(requestPath, "api/") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+
+		if requestPath == "" || requestPath == "index.html" {
+			c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+			return
+		}
+
+		if info, err := fs.Stat(files, requestPath); err != nil || info.IsDir() {
+			c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+			return
+		}
+
+		if strings.HasPrefix(requestPath, "assets/") {
+			c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		}
+		fileServer.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// indexWithBase injects a <base> element so the relative asset and API URLs
+// of the bundle resolve under basePath, whatever the depth of the route.
+func indexWithBase(files fs.FS, basePath string) []byte {
+	index, err := fs.ReadFile(files, "index.html")
+	if err != nil {
+		return nil
+	}
+
+	baseTag := fmt.Sprintf(`<base href="%s/">`, html.EscapeString(basePath))
+	return bytes.Replace(index, []byte("<head>"), []byte("<head>\n    "+baseTag), 1)
+}
@@ -1,5 +1,36 @@
 // API client for dashboard backend
-const API_BASE_URL = 'http://localhost:8080/api/v1';
+export const API_BASE_URL: string = import.meta.env.VITE_API_BASE_URL ?? 'api/v1';
+
+export type Breakpoint = 'desktop' | 'tablet' | 'mobile';
+
+export interface GridRect {
+  x: number;
+  y: number;
+  w: number;
+  h: number;
+}
+
+export type WidgetLayout = Partial<Record<Breakpoint, GridRect>>;
+
+export interface DashboardSection {
+  id: number;
+  dashboard_id: number;
+  title: string;
+  position: number;
+  collapsed: boolean;
+}
+
+export interface DashboardLayout {
+  columns: Record<Breakpoint, number>;
+  sections: DashboardSection[];
+  widgets: { id: number; section_id: number | null; position: number; layout: WidgetLayout }[];
+}
+
+export interface DashboardLayoutUpdate {
+  columns?: Partial<Record<Breakpoint, number>>;
+  sections?: { id: number; title?: string; position?: number; collapsed?: boolean }[];
+  widgets?: { id: number; section_id?: number | null; position?: number; layout?: WidgetLayout }[];
+}
 
 export interface DashboardWidget {
   id: number;
@@ -7,6 +38,8 @@ export interface DashboardWidget {
   name: string;
   type: string;
   position: number;
+  section_id?: number | null;
+  layout?: WidgetLayout;
   config: Record<string, any>;
   last_state?: Record<string, any>;
   is_enabled: boolean;
@@ -19,11 +52,29 @@ export interface Dashboard {
   name: string;
   description?: string;
   glances_config?: string;
+  columns?: Record<Breakpoint, number>;
+  sections?: DashboardSection[];
   created_at?: string;
   updated_at?: string;
   widgets?: DashboardWidget[];
 }
 
+export interface UserPreferences {
+  user: string;
+  default_dashboard_id: number | null;
+  theme: 'system' | 'light' | 'dark';
+  refresh_interval: number;
+  timezone: string;
+  units: 'metric' | 'imperial';
+}
+
+export interface Me {
+  user: string;
+  authenticated: boolean;
+  home_dashboard_id: number | null;
+  preferences: UserPreferences;
+}
+
 class DashboardAPI {
   private async request<T>(endpoint: string, options?: RequestInit): Promise<T> {
     const url = `${API_BASE_URL}${endpoint}`;
@@ -44,6 +95,18 @@ class DashboardAPI {
     return data.data || data;
   }
 
+  // User methods
+  async getMe(): Promise<Me> {
+    return this.request<Me>('/me');
+  }
+
+  async updatePreferences(preferences: Partial<Omit<UserPreferences, 'user'>>): Promise<UserPreferences> {
+    return this.request<UserPreferences>('/me/preferences', {
+      method: 'PUT',
+      body: JSON.stringify(preferences),
+    });
+  }
+
   // Dashboard methods
   async getDashboards(): Promise<Dashboard[]> {
     return this.request<Dashboard[]>('/dashboards');
@@ -73,6 +136,31 @@ class DashboardAPI {
     });
   }
 
+  // Layout methods
+  async getLayout(dashboardId: number): Promise<DashboardLayout> {
+    return this.request<DashboardLayout>(`/dashboards/${dashboardId}/layout`);
+  }
+
+  async updateLayout(dashboardId: number, layout: DashboardLayoutUpdate): Promise<DashboardLayout> {
+    return this.request<DashboardLayout>(`/dashboards/${dashboardId}/layout`, {
+      method: 'PUT',
+      body: JSON.stringify(layout),
+    });
+  }
+
+  async createSection(dashboardId: number, section: { title: string; collapsed?: boolean }): Promise<DashboardSection> {
+    return this.request<DashboardSection>(`/dashboards/${dashboardId}/sections`, {
+      method: 'POST',
+      body: JSON.stringify(section),
+    });
+  }
+
+  async deleteSection(dashboardId: number, sectionId: number): Promise<void> {
+    await this.request<void>(`/dashboards/${dashboardId}/sections/${sectionId}`, {
+      method: 'DELETE',
+    });
+  }
+
   // Widget methods
   async getWidgets(dashboardId: number): Promise<DashboardWidget[]> {
     return this.request<DashboardWidget[]>(`/dashboards/${dashboardId}/widgets`);
@@ -1,6 +1,7 @@
 import type { Plugin, PluginConfig, PluginData } from '../types.js';
 import AdGuardHomeWidget from './AdGuardHomeWidget.svelte';
 import { handleApiCall } from '../../utils/errors.js';
+import { API_BASE_URL } from '../../api/dashboard.js';
 
 export const plugin: Plugin = {
   metadata: {
@@ -74,7 +75,7 @@ export const plugin: Plugin = {
 
     const data = await handleApiCall(async () => {
       if (widgetId === undefined || test) {
-        const apiUrl = `http://localhost:8080/api/v1/adguard/test`;
+        const apiUrl = `${API_BASE_URL}/adguard/test`;
         return fetch(apiUrl, {
           method: 'POST',
           headers: {
@@ -83,7 +84,7 @@ export const plugin: Plugin = {
           body: JSON.stringify(config)
         });
       } else {
-        const apiUrl = `http://localhost:8080/api/v1/adguard/${widgetId}`;
+        const apiUrl = `${API_BASE_URL}/adguard/${widgetId}`;
         return fetch(apiUrl, {
           method: 'GET',
           headers: {
@@ -0,0 +1,117 @@
+import type { Plugin, PluginConfig, PluginData } from '../types.js';
+import DockerWidget from './DockerWidget.svelte';
+import { handleApiCall } from '../../utils/errors.js';
+import { API_BASE_URL } from '../../api/dashboard.js';
+
+export const plugin: Plugin = {
+  metadata: {
+    id: 'docker',
+    name: 'Docker',
+    description: 'Monitor Docker containers, their health and resource usage',
+    version: '1.0.0',
+    author: 'Alex <https://x.com/_avdept>',
+    category: 'system',
+    icon: 'docker'
+  },
+
+  configTemplate: {
+    fields: [
+      {
+        key: 'title',
+        label: 'Card Title',
+        type: 'text',
+        required: false,
+        default: 'Docker',
+        description: 'The title displayed on the card',
+        placeholder: 'Enter card title'
+      },
+      {
+        key: 'endpoint',
+        label: 'Docker Endpoint',
+        type: 'text',
+        required: true,
+        default: 'unix:///var/run/docker.sock',
+        description: 'Unix socket (unix://), TCP (tcp://) or HTTP(S) URL of the Docker Engine API',
+        placeholder: 'unix:///var/run/docker.sock'
+      },
+      {
+        key: 'showStopped',
+        label: 'Show Stopped Containers',
+        type: 'boolean',
+        required: false,
+        default: false,
+        description: 'Include stopped containers in the list'
+      },
+      {
+        key: 'allowActions',
+        label: 'Allow Container Actions',
+        type: 'boolean',
+        required: false,
+        default: false,
+        description: 'Show start, stop and restart buttons for each container'
+      },
+      {
+        key: 'tlsSkipVerify',
+        label: 'Skip TLS Verification',
+        type: 'boolean',
+        required: false,
+        default: false,
+        description: 'Accept self-signed certificates for https:// endpoints'
+      },
+      {
+        key: 'refreshRate',
+        label: 'Refresh Rate (seconds)',
+        type: 'number',
+        required: false,
+        default: 30,
+        description: 'How often to refresh the data (10-300 seconds)'
+      }
+    ]
+  },
+
+  component: DockerWidget,
+
+  async fetchData(config: PluginConfig, widgetId?: string | number, test?: boolean): Promise<PluginData> {
+    const result = await handleApiCall(async () => {
+      if (widgetId === undefined || test) {
+        const apiUrl = `${API_BASE_URL}/docker/test`;
+        return fetch(apiUrl, {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+          },
+          body: JSON.stringify(config)
+        });
+      } else {
+        const apiUrl = `${API_BASE_URL}/docker/${widgetId}`;
+        return fetch(apiUrl, {
+          method: 'GET',
+          headers: {
+            'Content-Type': 'application/json',
+          }
+        });
+      }
+    }, 'Docker');
+
+    if (!result.success) {
+      throw new Error(result.error || 'Unknown error occurred');
+    }
+
+    return {
+      success: true,
+      data: result.data,
+      error: null
+    };
+  }
+};
+
+export async function containerAction(widgetId: string | number, containerId: string, action: 'start' | 'stop' | 'restart') {
+  const response = await fetch(`${API_BASE_URL}/docker/${widgetId}/containers/${containerId}/${action}`, {
+    method: 'POST'
+  });
+  const body = await response.json().catch(() => ({}));
+  if (!response.ok) {
+    throw new Error(body.error || `Failed to ${action} container`);
+  }
+  return body;
+}
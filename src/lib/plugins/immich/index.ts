@@ -1,6 +1,7 @@
 import type { Plugin, PluginConfig, PluginData } from '../types.js';
 import ImmichWidget from './ImmichWidget.svelte';
 import { handleApiCall } from '../../utils/errors.js';
+import { API_BASE_URL } from '../../api/dashboard.js';
 
 export const plugin: Plugin = {
   metadata: {
@@ -77,7 +78,7 @@ export const plugin: Plugin = {
   async fetchData(config: PluginConfig, widgetId?: string | number, test?: boolean): Promise<PluginData> {
     const data = await handleApiCall(async () => {
       if (widgetId === undefined || test) {
-        const apiUrl = `http://localhost:8080/api/v1/immich/test`;
+        const apiUrl = `${API_BASE_URL}/immich/test`;
         return fetch(apiUrl, {
           method: 'POST',
           headers: {
@@ -86,7 +87,7 @@ export const plugin: Plugin = {
           body: JSON.stringify(config)
         });
       } else {
-        const apiUrl = `http://localhost:8080/api/v1/immich/${widgetId}`;
+        const apiUrl = `${API_BASE_URL}/immich/${widgetId}`;
         return fetch(apiUrl, {
           method: 'GET',
           headers: {
@@ -0,0 +1,97 @@
+import type { Plugin, PluginConfig, PluginData } from '../types.js';
+import KubernetesWidget from './KubernetesWidget.svelte';
+import { handleApiCall } from '../../utils/errors.js';
+import { API_BASE_URL } from '../../api/dashboard.js';
+
+export const plugin: Plugin = {
+  metadata: {
+    id: 'kubernetes',
+    name: 'Kubernetes',
+    description: 'Monitor Kubernetes nodes, pods, volume claims and warning events',
+    version: '1.0.0',
+    author: 'Alex <https://x.com/_avdept>',
+    category: 'system',
+    icon: 'kubernetes'
+  },
+
+  configTemplate: {
+    fields: [
+      {
+        key: 'title',
+        label: 'Card Title',
+        type: 'text',
+        required: false,
+        default: 'Kubernetes',
+        description: 'The title displayed on the card',
+        placeholder: 'Enter card title'
+      },
+      {
+        key: 'context',
+        label: 'Kubeconfig Context',
+        type: 'text',
+        required: false,
+        description: 'Context from the server\'s kubeconfig. Leave empty for the current context or the in-cluster service account',
+        placeholder: 'default'
+      },
+      {
+        key: 'namespaces',
+        label: 'Namespaces',
+        type: 'text',
+        required: false,
+        description: 'Comma-separated namespaces to include. Leave empty for all namespaces',
+        placeholder: 'default,media'
+      },
+      {
+        key: 'eventWindow',
+        label: 'Event Window (minutes)',
+        type: 'number',
+        required: false,
+        default: 60,
+        description: 'How far back to show warning events'
+      },
+      {
+        key: 'refreshRate',
+        label: 'Refresh Rate (seconds)',
+        type: 'number',
+        required: false,
+        default: 30,
+        description: 'How often to refresh the data (10-300 seconds)'
+      }
+    ]
+  },
+
+  component: KubernetesWidget,
+
+  async fetchData(config: PluginConfig, widgetId?: string | number, test?: boolean): Promise<PluginData> {
+    const result = await handleApiCall(async () => {
+      if (widgetId === undefined || test) {
+        const apiUrl = `${API_BASE_URL}/kubernetes/test`;
+        return fetch(apiUrl, {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+          },
+          body: JSON.stringify(config)
+        });
+      } else {
+        const apiUrl = `${API_BASE_URL}/kubernetes/${widgetId}`;
+        return fetch(apiUrl, {
+          method: 'GET',
+          headers: {
+            'Content-Type': 'application/json',
+          }
+        });
+      }
+    }, 'Kubernetes');
+
+    if (!result.success) {
+      throw new Error(result.error || 'Unknown error occurred');
+    }
+
+    return {
+      success: true,
+      data: result.data,
+      error: null
+    };
+  }
+};
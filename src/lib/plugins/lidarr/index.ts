@@ -1,6 +1,7 @@
 import type { Plugin, PluginConfig, PluginData } from '../types.js';
 import LidarrWidget from './LidarrWidget.svelte';
 import { handleApiCall } from '../../utils/errors.js';
+import { API_BASE_URL } from '../../api/dashboard.js';
 
 export const plugin: Plugin = {
   metadata: {
@@ -78,7 +79,7 @@ export const plugin: Plugin = {
 
     const data = await handleApiCall(async () => {
       if (widgetId === undefined || test) {
-        const apiUrl = `http://localhost:8080/api/v1/lidarr/test`;
+        const apiUrl = `${API_BASE_URL}/lidarr/test`;
         return fetch(apiUrl, {
           method: 'POST',
           headers: {
@@ -87,7 +88,7 @@ export const plugin: Plugin = {
           body: JSON.stringify(config)
         });
       } else {
-        const apiUrl = `http://localhost:8080/api/v1/lidarr/${widgetId}`;
+        const apiUrl = `${API_BASE_URL}/lidarr/${widgetId}`;
         return fetch(apiUrl, {
           method: 'GET',
           headers: {
@@ -1,6 +1,7 @@
 import type { Plugin, PluginConfig, PluginData } from '../types.js';
 import ProwlarrWidget from './ProwlarrWidget.svelte';
 import { handleApiCall } from '../../utils/errors.js';
+import { API_BASE_URL } from '../../api/dashboard.js';
 
 export const plugin: Plugin = {
   metadata: {
@@ -59,7 +60,7 @@ export const plugin: Plugin = {
   async fetchData(config: PluginConfig, widgetId?: string | number, test?: boolean): Promise<PluginData> {
     const data = await handleApiCall(async () => {
       if (widgetId === undefined || test) {
-        const apiUrl = `http://localhost:8080/api/v1/prowlarr/test`;
+        const apiUrl = `${API_BASE_URL}/prowlarr/test`;
         return fetch(apiUrl, {
           method: 'POST',
           headers: {
@@ -68,7 +69,7 @@ export const plugin: Plugin = {
           body: JSON.stringify(config)
         });
       } else {
-        const apiUrl = `http://localhost:8080/api/v1/prowlarr/${widgetId}`;
+        const apiUrl = `${API_BASE_URL}/prowlarr/${widgetId}`;
         return fetch(apiUrl, {
           method: 'GET',
           headers: {
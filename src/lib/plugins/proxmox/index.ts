@@ -0,0 +1,114 @@
+import type { Plugin, PluginConfig, PluginData } from '../types.js';
+import ProxmoxWidget from './ProxmoxWidget.svelte';
+import { handleApiCall } from '../../utils/errors.js';
+import { API_BASE_URL } from '../../api/dashboard.js';
+
+export const plugin: Plugin = {
+  metadata: {
+    id: 'proxmox',
+    name: 'Proxmox VE',
+    description: 'Monitor Proxmox nodes, VMs, containers, storage and backups',
+    version: '1.0.0',
+    author: 'Alex <https://x.com/_avdept>',
+    icon: 'proxmox',
+    category: 'system',
+  },
+
+  configTemplate: {
+    fields: [
+      {
+        key: 'title',
+        label: 'Widget Title',
+        type: 'text',
+        required: false,
+        default: 'Proxmox',
+        credential: false,
+        placeholder: 'Custom title for this widget'
+      },
+      {
+        key: 'serverUrl',
+        label: 'Proxmox Server URL',
+        type: 'url',
+        required: true,
+        credential: false,
+        placeholder: 'https://192.168.1.10:8006',
+        description: 'The base URL of your Proxmox VE host or cluster node'
+      },
+      {
+        key: 'tokenId',
+        label: 'API Token ID',
+        type: 'text',
+        required: true,
+        credential: true,
+        placeholder: 'root@pam!dashboard',
+        description: 'Token ID in the form user@realm!name (Datacenter > Permissions > API Tokens)'
+      },
+      {
+        key: 'tokenSecret',
+        label: 'API Token Secret',
+        type: 'password',
+        required: true,
+        credential: true,
+        description: 'The secret shown when the token was created. The token needs the PVEAuditor role'
+      },
+      {
+        key: 'tlsSkipVerify',
+        label: 'Skip TLS Verification',
+        type: 'boolean',
+        required: false,
+        credential: false,
+        default: true,
+        description: 'Accept the self-signed certificate Proxmox uses by default'
+      },
+      {
+        key: 'backupWindow',
+        label: 'Backup Window (hours)',
+        type: 'number',
+        required: false,
+        credential: false,
+        default: 24,
+        description: 'How far back to look for failed backup tasks'
+      },
+      {
+        key: 'refreshRate',
+        label: 'Refresh Rate (seconds)',
+        type: 'number',
+        required: false,
+        credential: false,
+        default: 30,
+        description: 'How often to refresh the statistics (10-300 seconds)'
+      }
+    ]
+  },
+
+  component: ProxmoxWidget,
+
+  async fetchData(config: PluginConfig, widgetId?: string | number, test?: boolean): Promise<PluginData> {
+    const data = await handleApiCall(async () => {
+      if (widgetId === undefined || test) {
+        const apiUrl = `${API_BASE_URL}/proxmox/test`;
+        return fetch(apiUrl, {
+          method: 'POST',
+          headers: {
+            'Content-Type': 'application/json',
+          },
+          body: JSON.stringify(config)
+        });
+      } else {
+        const apiUrl = `${API_BASE_URL}/proxmox/${widgetId}`;
+        return fetch(apiUrl, {
+          method: 'GET',
+          headers: {
+            'Content-Type': 'application/json',
+          }
+        });
+      }
+    }, 'Proxmox');
+
+    return {
+      success: true,
+      data: data,
+      lastUpdated: new Date().toISOString()
+    };
+  }
+};
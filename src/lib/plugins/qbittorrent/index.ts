@@ -1,6 +1,7 @@
 import type { Plugin, PluginConfig, PluginData } from '../types.js';
 import QBittorrentWidget from './QBittorrentWidget.svelte';
 import { handleApiCall } from '../../utils/errors.js';
+import { API_BASE_URL } from '../../api/dashboard.js';
 
 export const plugin: Plugin = {
   metadata: {
@@ -80,7 +81,7 @@ export const plugin: Plugin = {
   async fetchData(config: PluginConfig, widgetId?: string | number, test?: boolean): Promise<PluginData> {
     const result = await handleApiCall(async () => {
       if (widgetId === undefined || test) {
-        const apiUrl = `http://localhost:8080/api/v1/qbittorrent/test`;
+        const apiUrl = `${API_BASE_URL}/qbittorrent/test`;
         return fetch(apiUrl, {
           method: 'POST',
           headers: {
@@ -89,7 +90,7 @@ export const plugin: Plugin = {
           body: JSON.stringify(config)
         });
       } else {
-        const apiUrl = `http://localhost:8080/api/v1/qbittorrent/${widgetId}`;
+        const apiUrl = `${API_BASE_URL}/qbittorrent/${widgetId}`;
         return fetch(apiUrl, {
           method: 'GET',
           headers: {
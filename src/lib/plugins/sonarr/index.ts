@@ -1,6 +1,7 @@
 import type { Plugin, PluginConfig, PluginData } from '../types.js';
 import SonarrWidget from './SonarrWidget.svelte';
 import { handleApiCall } from '../../utils/errors.js';
+import { API_BASE_URL } from '../../api/dashboard.js';
 
 export const plugin: Plugin = {
   metadata: {
@@ -77,7 +78,7 @@ export const plugin: Plugin = {
 
     const data = await handleApiCall(async () => {
       if (widgetId === undefined || test) {
-        const apiUrl = `http://localhost:8080/api/v1/sonarr/test`;
+        const apiUrl = `${API_BASE_URL}/sonarr/test`;
         return fetch(apiUrl, {
           method: 'POST',
           headers: {
@@ -86,7 +87,7 @@ export const plugin: Plugin = {
           body: JSON.stringify(config)
         });
       } else {
-        const apiUrl = `http://localhost:8080/api/v1/sonarr/${widgetId}`;
+        const apiUrl = `${API_BASE_URL}/sonarr/${widgetId}`;
         return fetch(apiUrl, {
           method: 'GET',
           headers: {
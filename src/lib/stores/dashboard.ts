@@ -41,19 +41,20 @@ class DashboardStore {
       isDashboardLoading.set(true);
       dashboardError.set(null);
 
-      const dashboards = await dashboardAPI.getDashboards();
+      const me = await dashboardAPI.getMe();
 
-      let dashboard: Dashboard;
-      if (dashboards.length > 0) {
-        dashboard = dashboards[0];
+      let dashboardId: number;
+      if (me.home_dashboard_id) {
+        dashboardId = me.home_dashboard_id;
       } else {
-        dashboard = await dashboardAPI.createDashboard({
+        const dashboard = await dashboardAPI.createDashboard({
           name: 'Main Dashboard',
           description: 'Primary monitoring dashboard'
         });
+        dashboardId = dashboard.id;
       }
 
-      const fullDashboard = await dashboardAPI.getDashboard(dashboard.id!);
+      const fullDashboard = await dashboardAPI.getDashboard(dashboardId);
 
       currentDashboard.set(fullDashboard);
       dashboardWidgets.set(fullDashboard.widgets || []);
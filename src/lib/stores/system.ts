@@ -1,6 +1,7 @@
 import { writable, derived } from 'svelte/store';
 import { pluginRegistry } from '../plugins/registry.js';
 import { pluginInstancesFromDB } from './dashboard.js';
+import { API_BASE_URL } from '../api/dashboard.js';
 import type { PluginInstance } from '../plugins/types.js';
 import { getPluginRefreshRate } from '../plugins/types.js';
 
@@ -56,7 +57,7 @@ function createSystemStatsStore() {
 
   async function fetchSystemStats(): Promise<void> {
     try {
-      const response = await fetch('http://localhost:8080/api/v1/system/stats');
+      const response = await fetch(`${API_BASE_URL}/system/stats`);
       if (response.ok) {
         const result = await response.json();
         if (result.success && result.data) {
@@ -4,4 +4,11 @@ import { svelte } from '@sveltejs/vite-plugin-svelte'
 // https://vite.dev/config/
 export default defineConfig({
   plugins: [svelte()],
+  // Relative asset URLs let the bundle be served from any base path
+  base: './',
+  server: {
+    proxy: {
+      '/api': 'http://localhost:8080',
+    },
+  },
 })